@@ -0,0 +1,259 @@
+/*
+ * Batch provisioning of servers from a manifest, with optional all-or-nothing rollback.
+ */
+package clcv1
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+	"fmt"
+)
+
+// One entry of a batch manifest: a server creation request, and how many servers to create from it.
+type BatchSpec struct {
+	CreateServerReq
+
+	// Number of identical servers to create from this spec (defaults to 1).
+	Count		int
+}
+
+// Load a batch manifest from JSON file @path, which contains an array of BatchSpec.
+func LoadBatchManifest(path string) (specs []BatchSpec, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read batch manifest: %s", err)
+	} else if err = json.Unmarshal(data, &specs); err != nil {
+		return nil, fmt.Errorf("Failed to decode batch manifest %s: %s", path, err)
+	}
+	return
+}
+
+// Options for BatchCreateServers.
+type BatchOptions struct {
+	// Maximum number of CreateServer requests in flight at the same time (at least 1).
+	Parallel	int
+
+	// If any creation fails, delete all servers of the batch that were created.
+	AllOrNothing	bool
+
+	// How often to check on the deployment and rollback requests (defaults to 10 seconds).
+	PollInterval	time.Duration
+
+	// How long to wait for each deployment and rollback request (defaults to DefaultRequestTimeout).
+	Timeout		time.Duration
+}
+
+// Outcome of a single CreateServer request of a batch.
+type BatchResult struct {
+	// Index of the spec in the manifest that this request was created from.
+	Spec		int
+
+	// The request ID of CreateServer (0 if it could not be submitted).
+	RequestID	int
+
+	// Name(s) of the server(s) created by this request, as reported by GetDeploymentStatus.
+	Servers		[]string
+
+	// Error, if the creation failed.
+	Err		error
+
+	// Whether the request was not submitted, since another request of the batch
+	// had already failed (AllOrNothing only).
+	Skipped		bool
+}
+
+// Outcome of BatchCreateServers.
+type BatchReport struct {
+	// One result per CreateServer request, in manifest order.
+	Results		[]BatchResult
+
+	// Servers that were deleted again, because the batch failed in all-or-nothing mode.
+	RolledBack	[]string
+
+	// Errors encountered during rollback; these servers may have to be deleted manually.
+	RollbackErrs	[]string
+}
+
+// Return the number of failed requests in @r.
+func (r *BatchReport) Failed() (n int) {
+	for i := range r.Results {
+		if r.Results[i].Err != nil {
+			n++
+		}
+	}
+	return
+}
+
+// Return the number of requests in @r that were not submitted (see BatchResult.Skipped).
+func (r *BatchReport) Skipped() (n int) {
+	for i := range r.Results {
+		if r.Results[i].Skipped {
+			n++
+		}
+	}
+	return
+}
+
+// Return the names of the servers that were created and not rolled back.
+func (r *BatchReport) Created() (names []string) {
+	var gone = make(map[string]bool)
+
+	for _, name := range r.RolledBack {
+		gone[name] = true
+	}
+	for i := range r.Results {
+		for _, name := range r.Results[i].Servers {
+			if !gone[name] {
+				names = append(names, name)
+			}
+		}
+	}
+	return
+}
+
+// Create the servers described by @specs.
+// Each spec is validated (see CreateServerReq.Validate) before any request is submitted.
+// With @opts.AllOrNothing, requests that have not been submitted when the first request fails are
+// skipped, and the servers created by the other requests are deleted again.
+// The error return is only set if the batch could not be started; individual failures are in the report.
+func (c *Client) BatchCreateServers(specs []BatchSpec, opts BatchOptions) (*BatchReport, error) {
+	var report = &BatchReport{}
+	var mu     sync.Mutex
+	var wg     sync.WaitGroup
+	var v      ValidationError
+	var failed bool
+
+	for i := range specs {
+		if specs[i].Count < 0 {
+			v.add("Spec #%d: invalid count %d", i + 1, specs[i].Count)
+		}
+		if err := specs[i].Validate(); err != nil {
+			if problems, ok := err.(ValidationError); ok {
+				for _, p := range problems {
+					v.add("Spec #%d: %s", i + 1, p)
+				}
+			} else {
+				v.add("Spec #%d: %s", i + 1, err)
+			}
+		}
+		count := specs[i].Count
+		if count == 0 {
+			count = 1
+		}
+		for j := 0; j < count; j++ {
+			report.Results = append(report.Results, BatchResult{ Spec: i })
+		}
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	if opts.Parallel < 1 {
+		opts.Parallel = 1
+	}
+	sem := make(chan struct{}, opts.Parallel)
+
+	for i := range report.Results {
+		wg.Add(1)
+		go func(res *BatchResult) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			mu.Lock()
+			res.Skipped = opts.AllOrNothing && failed
+			mu.Unlock()
+			if res.Skipped {
+				return
+			}
+
+			/* Each request gets its own copy, since CreateServer may be called concurrently */
+			req := specs[res.Spec].CreateServerReq
+			if res.Err = c.batchCreate(res, &req, opts); res.Err != nil {
+				mu.Lock()
+				failed = true
+				mu.Unlock()
+			}
+		}(&report.Results[i])
+	}
+	wg.Wait()
+
+	if opts.AllOrNothing && report.Failed() > 0 {
+		c.batchRollback(report, specs, opts)
+	}
+	return report, nil
+}
+
+// Submit a single CreateServer request @req and wait for it, filling in @res.
+func (c *Client) batchCreate(res *BatchResult, req *CreateServerReq, opts BatchOptions) (err error) {
+	if res.RequestID, err = c.CreateServer(req); err != nil {
+		return fmt.Errorf("Failed to submit server creation: %s", err)
+	}
+
+	status, err := c.WaitForDeployment(res.RequestID, req.AccountAlias, req.LocationAlias, opts.PollInterval, opts.Timeout)
+	if status != nil {
+		/* A failed deployment may still have created the server, which then has to be rolled back */
+		res.Servers = status.Servers
+	}
+	return err
+}
+
+// Delete all servers created by the batch of @report, recording the outcome in @report.
+// Only the servers reported by the deployment of submitted requests are deleted.
+func (c *Client) batchRollback(report *BatchReport, specs []BatchSpec, opts BatchOptions) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, opts.Parallel)
+	for i := range report.Results {
+		acctAlias := specs[report.Results[i].Spec].AccountAlias
+
+		for _, name := range report.Results[i].Servers {
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				reqId, err := c.DeleteServer(name, acctAlias)
+				if err == nil {
+					_, err = c.WaitForRequest(reqId, opts.PollInterval, opts.Timeout)
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					report.RollbackErrs = append(report.RollbackErrs, fmt.Sprintf("%s: %s", name, err))
+				} else {
+					report.RolledBack = append(report.RolledBack, name)
+				}
+			}(name)
+		}
+	}
+	wg.Wait()
+}
+
+// Summarize @r in one line, e.g. "10 request(s), 2 failed, 8 server(s) rolled back".
+func (r *BatchReport) String() string {
+	var parts = []string{ fmt.Sprintf("%d request(s)", len(r.Results)) }
+
+	if n := r.Failed(); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", n))
+	}
+	if n := r.Skipped(); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", n))
+	}
+	if n := len(r.Created()); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d server(s) created", n))
+	}
+	if len(r.RolledBack) > 0 {
+		parts = append(parts, fmt.Sprintf("%d server(s) rolled back", len(r.RolledBack)))
+	}
+	if len(r.RollbackErrs) > 0 {
+		parts = append(parts, fmt.Sprintf("%d rollback failure(s)", len(r.RollbackErrs)))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -10,6 +10,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"reflect"
+	"regexp"
 	"bytes"
 	"time"
 	"flag"
@@ -25,6 +26,9 @@ const (
 // Global variables
 var g_debug  bool     /* Command-line debug flag */
 
+/* JSON attributes whose values must not appear in debug output */
+var secretsRegexp = regexp.MustCompile(`("[A-Za-z]*Password"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
 func init() {
 	flag.BoolVar(&g_debug,    "d", false, "Produce debug output")
 }
@@ -70,13 +74,12 @@ func (c *Client) getResponse(path string, reqModel interface{}, resModel interfa
 	var reqBody io.Reader
 
 	if reqModel != nil {
-		if g_debug {
-			c.Log.Printf("reqModel %T %+v\n", reqModel, reqModel)
-		}
-
 		jsonReq, err := json.Marshal(reqModel)
 		if err != nil {
-			return fmt.Errorf("Failed to encode request model %T %+v: %s", reqModel, reqModel, err)
+			return fmt.Errorf("Failed to encode request model %T: %s", reqModel, err)
+		}
+		if g_debug {
+			c.Log.Printf("reqModel %T %s\n", reqModel, redactSecrets(jsonReq))
 		}
 		reqBody = bytes.NewBuffer(jsonReq)
 	}
@@ -99,7 +102,7 @@ func (c *Client) getResponse(path string, reqModel interface{}, resModel interfa
 
 	if g_debug {
 		reqDump, _ := httputil.DumpRequest(req, true)
-		c.Log.Printf("%s", reqDump)
+		c.Log.Printf("%s", redactSecrets(reqDump))
 	}
 
 	res, err := c.Do(req)
@@ -110,7 +113,7 @@ func (c *Client) getResponse(path string, reqModel interface{}, resModel interfa
 
 	if g_debug {
 		resDump, _ := httputil.DumpResponse(res, true)
-		c.Log.Printf("%s", resDump)
+		c.Log.Printf("%s", redactSecrets(resDump))
 	}
 
 	/* StatusCode is used instead of the HTTP status code (which is 200 even if there was an error) */
@@ -129,3 +132,8 @@ func (c *Client) getResponse(path string, reqModel interface{}, resModel interfa
 
 	return br.Evaluate()
 }
+
+// Replace the values of password attributes in the JSON data @b, for debug output.
+func redactSecrets(b []byte) []byte {
+	return secretsRegexp.ReplaceAll(b, []byte(`$1"********"`))
+}
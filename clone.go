@@ -0,0 +1,163 @@
+/*
+ * Build server creation requests from existing servers.
+ */
+package clcv1
+
+import (
+	"strings"
+	"sort"
+	"fmt"
+)
+
+// Build a CreateServerReq that reproduces the configuration of the existing server @name.
+// @acctAlias: The alias of the account that owns the server (optional).
+// The request copies location, group, CPU, memory, server type/service level, description and
+// custom fields from the server. The template is one with the same operating system that is
+// available at the server's location; the network is the one containing the server's primary IP;
+// the extra drive is the storage the server has in addition to the disks of the template (an error is
+// returned if that storage consists of more than one disk, which a single extra drive can not reproduce).
+// The alias (seed) is derived from the server name. Callers can override any of these fields.
+func (c *Client) CloneServerSpec(name, acctAlias string) (req *CreateServerReq, err error) {
+	server, err := c.GetServer(name, acctAlias)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to look up server %s: %s", name, err)
+	}
+
+	req = &CreateServerReq{
+		AccountAlias:      acctAlias,
+		LocationAlias:     server.Location,
+		Description:       server.Description,
+		HardwareGroupUUID: server.HardwareGroupUUID,
+		ServerType:        server.ServerType,
+		ServiceLevel:      server.ServiceLevel,
+		Cpu:               server.Cpu,
+		MemoryGB:          server.MemoryGB,
+		CustomFields:      ServerCustomFieldValues(server.CustomFields),
+	}
+
+	templ, err := c.findCloneTemplate(&server, acctAlias)
+	if err != nil {
+		return nil, err
+	}
+	req.Template = templ.Name
+
+	disks, err := c.getServerDisks(&server, acctAlias)
+	if err != nil {
+		return nil, err
+	}
+	if req.ExtraDriveGB, err = extraStorageGB(disks, templ); err != nil {
+		return nil, fmt.Errorf("Server %s: %s", server.Name, err)
+	}
+
+	if req.Network, err = c.findServerNetwork(&server, acctAlias); err != nil {
+		return nil, err
+	}
+
+	if req.Alias, err = c.serverSeed(&server, acctAlias); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Find a template at the location of @s that has the same operating system as @s.
+// It is an error if no such template exists. Of several candidates, the one with the
+// smallest disk footprint is preferred, since additional storage is added separately.
+func (c *Client) findCloneTemplate(s *Server, acctAlias string) (*ServerTemplate, error) {
+	var candidates []ServerTemplate
+
+	templates, err := c.ListAvailableServerTemplates(acctAlias, s.Location)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list templates at %s: %s", s.Location, err)
+	}
+
+	for _, t := range templates {
+		if t.OperatingSystem == s.OperatingSystem {
+			candidates = append(candidates, t)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("No template with operating system %s is available at %s", s.OperatingSystem, s.Location)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].TotalDiskSpaceGB != candidates[j].TotalDiskSpaceGB {
+			return candidates[i].TotalDiskSpaceGB < candidates[j].TotalDiskSpaceGB
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	return &candidates[0], nil
+}
+
+// Compute the size of the extra drive that reproduces the storage in @disks not provided by the template
+// @templ. The template disks are the first templ.DiskCount of @disks, which are in SCSI address order
+// (see getServerDisks). Since CreateServer only supports a single extra drive, it is an error if there
+// is more than one such disk.
+func extraStorageGB(disks []Disk, templ *ServerTemplate) (int, error) {
+	var extra []Disk
+
+	if len(disks) > templ.DiskCount {
+		extra = disks[templ.DiskCount:]
+	}
+
+	switch {
+	case len(extra) == 0:
+		return 0, nil
+	case len(extra) > 1:
+		var names []string
+
+		for _, d := range extra {
+			names = append(names, fmt.Sprintf("%s (%d GB)", d.ScsiAddress, d.SizeGB))
+		}
+		return 0, fmt.Errorf("Can not reproduce %d additional disks %s with a single extra drive",
+				     len(extra), strings.Join(names, ", "))
+	}
+	return extra[0].SizeGB, nil
+}
+
+// Look up the name of the network that contains the primary IP address of @s.
+// Returns an empty name if @s has no IP address, or the network could not be found.
+func (c *Client) findServerNetwork(s *Server, acctAlias string) (string, error) {
+	if s.IPAddress == "" {
+		return "", nil
+	}
+
+	nets, err := c.GetAccountNetworks(acctAlias, s.Location)
+	if err != nil {
+		return "", fmt.Errorf("Failed to list networks at %s: %s", s.Location, err)
+	}
+
+	for _, n := range nets {
+		details, err := c.GetNetworkDetails(n.Name, acctAlias, s.Location)
+		if err != nil {
+			return "", fmt.Errorf("Failed to look up network %s: %s", n.Name, err)
+		}
+		for _, ip := range details.IPAddresses {
+			if ip.Address == s.IPAddress {
+				return n.Name, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// Derive the alias (seed) from the name of @s, which has the form
+// <Location><Account Alias><Seed><2-digit number>, e.g. WA1ABCDWEB01 -> WEB.
+func (c *Client) serverSeed(s *Server, acctAlias string) (string, error) {
+	var seed = strings.ToUpper(s.Name)
+
+	if acctAlias == "" {
+		details, err := c.GetAccountDetails("")
+		if err != nil {
+			return "", fmt.Errorf("Failed to look up account alias: %s", err)
+		}
+		acctAlias = details.AccountAlias
+	}
+
+	seed = strings.TrimPrefix(seed, strings.ToUpper(s.Location))
+	seed = strings.TrimPrefix(seed, strings.ToUpper(acctAlias))
+	seed = strings.TrimRight(seed, "0123456789")
+	if len(seed) > MaxAliasLength {
+		seed = seed[:MaxAliasLength]
+	}
+	return seed, nil
+}
@@ -0,0 +1,167 @@
+/*
+ * Cron-style schedules: "<minute> <hour> <day-of-month> <month> <day-of-week>".
+ */
+package clcv1
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"fmt"
+)
+
+// A parsed cron schedule. Each field is a bit set of the values that match.
+type CronSchedule struct {
+	// The original schedule specification.
+	Spec		string
+
+	minute, hour	uint64
+	dom, month, dow	uint64
+
+	// Whether day-of-month/day-of-week were restricted (i.e. not "*").
+	domRestricted	bool
+	dowRestricted	bool
+}
+
+var cronMonths = []string{ "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec" }
+var cronDays   = []string{ "sun", "mon", "tue", "wed", "thu", "fri", "sat" }
+
+// Parse the 5-field cron specification @spec, e.g. "0 19 * * mon-fri".
+// Each field supports "*", single values, ranges ("1-5"), lists ("1,3,5") and steps ("*/15", "8-18/2").
+// Months and days of the week may also be given by their three-letter English names.
+// Day-of-week 0 and 7 both denote Sunday. As in cron, if both day-of-month and day-of-week
+// are restricted, a day matches if either of them matches.
+func ParseCronSchedule(spec string) (*CronSchedule, error) {
+	var s = &CronSchedule{ Spec: spec }
+	var fields = strings.Fields(spec)
+	var err error
+
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("Invalid schedule %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	if s.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("Invalid minute in schedule %q: %s", spec, err)
+	} else if s.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("Invalid hour in schedule %q: %s", spec, err)
+	} else if s.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("Invalid day of month in schedule %q: %s", spec, err)
+	} else if s.month, err = parseCronField(fields[3], 1, 12, cronMonths); err != nil {
+		return nil, fmt.Errorf("Invalid month in schedule %q: %s", spec, err)
+	} else if s.dow, err = parseCronField(fields[4], 0, 7, cronDays); err != nil {
+		return nil, fmt.Errorf("Invalid day of week in schedule %q: %s", spec, err)
+	}
+
+	/* Sunday is both 0 and 7 */
+	if s.dow & (1 << 7) != 0 {
+		s.dow |= 1
+	}
+	s.domRestricted = fields[2] != "*"
+	s.dowRestricted = fields[4] != "*"
+	return s, nil
+}
+
+// Parse a single cron field with values in @min..@max; @names, if set, are the names of @min, @min+1, ...
+func parseCronField(field string, min, max int, names []string) (set uint64, err error) {
+	for _, elem := range strings.Split(field, ",") {
+		var lo, hi, step = min, max, 1
+
+		if idx := strings.Index(elem, "/"); idx >= 0 {
+			if step, err = strconv.Atoi(elem[idx+1:]); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in %q", elem)
+			}
+			elem = elem[:idx]
+		}
+
+		if elem != "*" {
+			bounds := strings.SplitN(elem, "-", 2)
+			if lo, err = parseCronValue(bounds[0], min, max, names); err != nil {
+				return 0, err
+			}
+			if hi = lo; len(bounds) == 2 {
+				if hi, err = parseCronValue(bounds[1], min, max, names); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				/* "n/step" means "n-max/step" */
+				hi = max
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q", elem)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// Parse a single cron value, either numeric or one of @names.
+func parseCronValue(s string, min, max int, names []string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(s, name) {
+			return min + i, nil
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	} else if v < min || v > max {
+		return 0, fmt.Errorf("value %d out of range %d..%d", v, min, max)
+	}
+	return v, nil
+}
+
+func (s *CronSchedule) String() string {
+	return s.Spec
+}
+
+// Return true if the day of @t matches the day-of-month/day-of-week fields of @s.
+func (s *CronSchedule) matchDay(t time.Time) bool {
+	var domOk = s.dom & (1 << uint(t.Day())) != 0
+	var dowOk = s.dow & (1 << uint(t.Weekday())) != 0
+
+	if s.domRestricted && s.dowRestricted {
+		return domOk || dowOk
+	}
+	return domOk && dowOk
+}
+
+// Return true if @s matches the minute of @t.
+func (s *CronSchedule) Matches(t time.Time) bool {
+	return s.month & (1 << uint(t.Month())) != 0 && s.matchDay(t) &&
+	       s.hour & (1 << uint(t.Hour())) != 0 && s.minute & (1 << uint(t.Minute())) != 0
+}
+
+// Return the first time after @t that matches @s, or the zero time if there is none within 5 years
+// (e.g. for "0 0 30 feb *").
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	var limit = t.AddDate(5, 0, 0)
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for t.Before(limit) {
+		if s.month & (1 << uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month() + 1, 1, 0, 0, 0, 0, t.Location())
+		} else if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day() + 1, 0, 0, 0, 0, t.Location())
+		} else if s.hour & (1 << uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour() + 1, 0, 0, 0, t.Location())
+		} else if s.minute & (1 << uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+		} else {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Return the last time in the interval (@after, @until] that matches @s, or the zero time if there is none.
+func (s *CronSchedule) Last(after, until time.Time) (last time.Time) {
+	for t := s.Next(after); !t.IsZero() && !t.After(until); t = s.Next(t) {
+		last = t
+	}
+	return
+}
@@ -0,0 +1,184 @@
+/*
+ * Name-based access to server custom fields.
+ */
+package clcv1
+
+import (
+	"strconv"
+	"strings"
+	"sort"
+	"fmt"
+)
+
+// CustomFieldResolver maps friendly custom field names to the account custom field definitions.
+type CustomFieldResolver struct {
+	// Field definitions, indexed by lower-case name.
+	byName	map[string]*AccountCustomField
+
+	// Field definitions, indexed by UUID.
+	byID	map[string]*AccountCustomField
+}
+
+// Create a resolver from the account custom field definitions @defs.
+func NewCustomFieldResolver(defs []AccountCustomField) *CustomFieldResolver {
+	var r = &CustomFieldResolver{
+		byName: make(map[string]*AccountCustomField),
+		byID:   make(map[string]*AccountCustomField),
+	}
+
+	for i := range defs {
+		r.byName[strings.ToLower(defs[i].Name)] = &defs[i]
+		r.byID[defs[i].UUID] = &defs[i]
+	}
+	return r
+}
+
+// Load the custom field definitions of @acctAlias (optional) via GetCustomFields.
+func (c *Client) LoadCustomFieldResolver(acctAlias string) (*CustomFieldResolver, error) {
+	defs, err := c.GetCustomFields(acctAlias)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to look up custom fields: %s", err)
+	}
+	return NewCustomFieldResolver(defs), nil
+}
+
+// Return the definitions of all custom fields, sorted by name.
+func (r *CustomFieldResolver) Definitions() (defs []*AccountCustomField) {
+	for _, d := range r.byName {
+		defs = append(defs, d)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return
+}
+
+// Look up the definition of the custom field @name (case-insensitive).
+func (r *CustomFieldResolver) Lookup(name string) (*AccountCustomField, error) {
+	if d, ok := r.byName[strings.ToLower(name)]; ok {
+		return d, nil
+	}
+	return nil, fmt.Errorf("No custom field named %q is defined on the account", name)
+}
+
+// Convert @value of custom field @name into its request representation.
+// Option fields accept either the option value or its name; Checkbox fields accept
+// any boolean value understood by strconv.ParseBool.
+func (r *CustomFieldResolver) Value(name, value string) (v CustomFieldValue, err error) {
+	d, err := r.Lookup(name)
+	if err != nil {
+		return v, err
+	}
+
+	switch d.CustomFieldType {
+	case "Checkbox":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return v, fmt.Errorf("Custom field %q: invalid checkbox value %q", d.Name, value)
+		}
+		value = strconv.FormatBool(b)
+	case "Option":
+		for _, o := range d.AccountCustomFieldOptions {
+			if strings.EqualFold(o.Name, value) && o.Value != value {
+				value = o.Value
+				break
+			}
+		}
+	}
+
+	if err = checkCustomFieldValue(d, value); err != nil {
+		return v, err
+	}
+	return CustomFieldValue{ ID: d.UUID, Value: value }, nil
+}
+
+// Convert the name/value pairs in @values into their request representation.
+// Returns a ValidationError listing all invalid entries.
+func (r *CustomFieldResolver) Values(values map[string]string) (res []CustomFieldValue, err error) {
+	var v     ValidationError
+	var names []string
+
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if cfv, err := r.Value(name, values[name]); err != nil {
+			v.add("%s", err)
+		} else {
+			res = append(res, cfv)
+		}
+	}
+	return res, v.err()
+}
+
+// Set custom field @name to @value in @fields, replacing an existing setting of the same field.
+func (r *CustomFieldResolver) Set(fields []CustomFieldValue, name, value string) ([]CustomFieldValue, error) {
+	cfv, err := r.Value(name, value)
+	if err != nil {
+		return fields, err
+	}
+
+	for i := range fields {
+		if fields[i].ID == cfv.ID {
+			fields[i].Value = cfv.Value
+			return fields, nil
+		}
+	}
+	return append(fields, cfv), nil
+}
+
+// Return the value of custom field @name in @fields, and whether it is set.
+func (r *CustomFieldResolver) Get(fields []CustomField, name string) (value string, ok bool) {
+	d, err := r.Lookup(name)
+	if err != nil {
+		return "", false
+	}
+
+	for _, f := range fields {
+		if f.ID == d.UUID {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+// Convert the Server.CustomFields representation @fields into a map of name -> value.
+func (r *CustomFieldResolver) ToMap(fields []CustomField) map[string]string {
+	var m = make(map[string]string)
+
+	for _, f := range fields {
+		if d, ok := r.byID[f.ID]; ok {
+			m[d.Name] = f.Value
+		} else {
+			m[f.Name] = f.Value
+		}
+	}
+	return m
+}
+
+// Convert the Server.CustomFields representation @fields into the request representation,
+// e.g. to preserve the existing custom fields of a server in a ConfigureServerReq.
+func ServerCustomFieldValues(fields []CustomField) (res []CustomFieldValue) {
+	for _, f := range fields {
+		res = append(res, CustomFieldValue{ ID: f.ID, Value: f.Value })
+	}
+	return
+}
+
+// Convert the request representation @values into the Server.CustomFields representation.
+func (r *CustomFieldResolver) ToServer(values []CustomFieldValue) (res []CustomField, err error) {
+	for _, v := range values {
+		d, ok := r.byID[v.ID]
+		if !ok {
+			return nil, fmt.Errorf("Custom field %s is not defined on the account", v.ID)
+		}
+		res = append(res, CustomField{
+			ID:            v.ID,
+			Name:          d.Name,
+			Type:          d.CustomFieldType,
+			Value:         v.Value,
+			CustomFieldID: -1,
+		})
+	}
+	return
+}
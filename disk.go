@@ -0,0 +1,231 @@
+/*
+ * High-level disk management on top of ListDisks, DeleteDisk and ResizeDisk.
+ */
+package clcv1
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"sort"
+	"fmt"
+)
+
+// SCSI address of a disk.
+type ScsiAddress struct {
+	// The SCSI bus ID of the disk.
+	Bus	int
+
+	// The SCSI device ID of the disk.
+	Device	int
+}
+
+func (a ScsiAddress) String() string {
+	return fmt.Sprintf("%d:%d", a.Bus, a.Device)
+}
+
+// Parse the SCSI bus ID @bus and device ID @dev, as reported by ListDisks.
+func ParseScsiAddress(bus, dev string) (a ScsiAddress, err error) {
+	if a.Bus, err = strconv.Atoi(strings.TrimSpace(bus)); err != nil {
+		return a, fmt.Errorf("Invalid SCSI bus ID %q", bus)
+	} else if a.Device, err = strconv.Atoi(strings.TrimSpace(dev)); err != nil {
+		return a, fmt.Errorf("Invalid SCSI device ID %q", dev)
+	}
+	return
+}
+
+// Parse a SCSI address of the form "<bus>:<device>", e.g. "0:2".
+func ParseScsiAddressString(s string) (ScsiAddress, error) {
+	if parts := strings.Split(s, ":"); len(parts) == 2 {
+		return ParseScsiAddress(parts[0], parts[1])
+	}
+	return ScsiAddress{}, fmt.Errorf("Invalid SCSI address %q (expected <bus>:<device>)", s)
+}
+
+// Return true if @a is one of the disks that DeleteDisk treats as primary operating system drive,
+// i.e. SCSI 0:0 on Windows (typically C drive), and SCSI 0:0, 0:1, 0:2 on Linux (boot, swap, root).
+func (a ScsiAddress) IsOSDisk(os OperatingSystem) bool {
+	if a.Bus != 0 {
+		return false
+	} else if os.IsWindows() {
+		return a.Device == 0
+	}
+	/* Linux/BSD - also used for unknown operating systems, to err on the side of caution */
+	return a.Device <= 2
+}
+
+// A disk attached to a server.
+type Disk struct {
+	// The name of the server the disk belongs to.
+	Server		string
+
+	// The operating system of @Server.
+	OperatingSystem	OperatingSystem
+
+	// SCSI bus and device ID of the disk.
+	ScsiAddress
+
+	// Guest mount point / drive letter of the disk (if known).
+	MountName	string
+
+	// Size of the disk in GB.
+	SizeGB		int
+
+	// Whether this is one of the primary operating system disks (see ScsiAddress.IsOSDisk).
+	IsOSDisk	bool
+}
+
+func (d Disk) String() string {
+	if d.MountName != "" {
+		return fmt.Sprintf("%s %s (%s, %d GB)", d.Server, d.ScsiAddress, d.MountName, d.SizeGB)
+	}
+	return fmt.Sprintf("%s %s (%d GB)", d.Server, d.ScsiAddress, d.SizeGB)
+}
+
+// List the disks of server @name, including guest mount names.
+// @acctAlias: The alias of the account that owns the server (optional).
+func (c *Client) GetDisks(name, acctAlias string) (disks []Disk, err error) {
+	server, err := c.GetServer(name, acctAlias)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to look up server %s: %s", name, err)
+	}
+	return c.getServerDisks(&server, acctAlias)
+}
+
+// List the disks of @server, using its OperatingSystem for classification.
+func (c *Client) getServerDisks(server *Server, acctAlias string) (disks []Disk, err error) {
+	_, info, err := c.ListDisks(server.Name, acctAlias, true)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list disks of %s: %s", server.Name, err)
+	}
+
+	for _, di := range info {
+		addr, err := ParseScsiAddress(di.ScsiBusID, di.ScsiDeviceID)
+		if err != nil {
+			return nil, fmt.Errorf("Disk %q of %s: %s", di.Name, server.Name, err)
+		}
+		disks = append(disks, Disk{
+			Server:          server.Name,
+			OperatingSystem: server.OperatingSystem,
+			ScsiAddress:     addr,
+			MountName:       di.Name,
+			SizeGB:          di.SizeGB,
+			IsOSDisk:        addr.IsOSDisk(server.OperatingSystem),
+		})
+	}
+	sort.Slice(disks, func(i, j int) bool {
+		if disks[i].Bus != disks[j].Bus {
+			return disks[i].Bus < disks[j].Bus
+		}
+		return disks[i].Device < disks[j].Device
+	})
+	return
+}
+
+// Normalize the mount point / drive letter @m for comparison, e.g. "c:\" -> "C:", "/data/" -> "/data".
+func normalizeMountName(m string) string {
+	m = strings.TrimSpace(m)
+	if len(m) >= 2 && m[1] == ':' {
+		/* Windows drive letter */
+		return strings.ToUpper(strings.TrimRight(m, `\/`))
+	} else if m != "/" {
+		m = strings.TrimRight(m, "/")
+	}
+	return m
+}
+
+// Look up the disk mounted at @mount (mount point or drive letter) in @disks; nil if not found.
+func FindDiskByMount(disks []Disk, mount string) *Disk {
+	var want = normalizeMountName(mount)
+
+	for i := range disks {
+		if disks[i].MountName != "" && normalizeMountName(disks[i].MountName) == want {
+			return &disks[i]
+		}
+	}
+	return nil
+}
+
+// Look up the disk with SCSI address @addr in @disks; nil if not found.
+func FindDiskByAddress(disks []Disk, addr ScsiAddress) *Disk {
+	for i := range disks {
+		if disks[i].ScsiAddress == addr {
+			return &disks[i]
+		}
+	}
+	return nil
+}
+
+// Grow disk @d to @targetGB.
+// @acctAlias: The alias of the account that owns the server (optional).
+// @expandFS:  Whether to expand the file system on the disk after the resize.
+// Returns a request ID of 0 if the disk is already at least @targetGB in size.
+func (c *Client) GrowDisk(d *Disk, acctAlias string, targetGB int, expandFS bool) (reqId int, err error) {
+	if targetGB <= d.SizeGB {
+		return 0, nil
+	}
+	return c.ResizeDisk(d.Server, acctAlias, strconv.Itoa(d.Bus), strconv.Itoa(d.Device), targetGB, expandFS)
+}
+
+// Remove disk @d from its server.
+// @acctAlias:         The alias of the account that owns the server (optional).
+// @allowOS:           Must be set to delete a primary operating system disk (see ScsiAddress.IsOSDisk).
+//                     This only lifts the client-side check; the server-side failsafes still apply.
+// @overrideFailsafes: Override the server-side failsafes of DeleteDisk (see DeleteDisk @force).
+// Deleting an operating system disk hence requires both @allowOS and @overrideFailsafes.
+func (c *Client) RemoveDisk(d *Disk, acctAlias string, allowOS, overrideFailsafes bool) (reqId int, err error) {
+	if d.IsOSDisk && !allowOS {
+		return 0, fmt.Errorf("Refusing to delete operating system disk %s", d)
+	}
+	return c.DeleteDisk(d.Server, acctAlias, strconv.Itoa(d.Bus), strconv.Itoa(d.Device), overrideFailsafes)
+}
+
+// Build an inventory of the disks of all servers matching the arguments of GetAllServers.
+// @acctAlias, @hwGrpUUID, @location: see GetAllServers (all optional).
+// @parallel: maximum number of concurrent ListDisks calls (at least 1).
+// Servers whose disks could not be listed are reported via @err, the remaining disks are still returned.
+func (c *Client) DiskInventory(acctAlias, hwGrpUUID, location string, parallel int) (disks []Disk, err error) {
+	var mu   sync.Mutex
+	var wg   sync.WaitGroup
+	var errs []string
+
+	servers, err := c.GetAllServers(acctAlias, hwGrpUUID, location)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list servers: %s", err)
+	}
+
+	if parallel < 1 {
+		parallel = 1
+	}
+	sem := make(chan struct{}, parallel)
+
+	for i := range servers {
+		if servers[i].IsTemplate {
+			continue
+		}
+		wg.Add(1)
+		go func(s *Server) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			d, err := c.getServerDisks(s, acctAlias)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err.Error())
+			} else {
+				disks = append(disks, d...)
+			}
+		}(&servers[i])
+	}
+	wg.Wait()
+
+	sort.SliceStable(disks, func(i, j int) bool { return disks[i].Server < disks[j].Server })
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		err = fmt.Errorf("Failed to list disks of %d server(s): %s", len(errs), strings.Join(errs, "; "))
+	}
+	return
+}
@@ -16,10 +16,11 @@ import (
 
 func main() {
 	var acctAlias = flag.String("a", "", "Account alias to use")
+	var location = flag.String("l", "", "Data centre location (needed to resolve group names)")
 	var simple = flag.Bool("simple", false, "Use simple (debugging) output format")
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "usage: %s [options]  <Hardware Group UUID>\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "usage: %s [options]  <HW Group UUID|Name|Path>\n", path.Base(os.Args[0]))
 		flag.PrintDefaults()
 	}
 
@@ -36,7 +37,13 @@ func main() {
 		exit.Fatalf("Login failed: %s", err)
 	}
 
-	grpEst, err := client.GetGroupEstimate(flag.Arg(0), *acctAlias)
+	/* The group may also be given by name or path, e.g. "WA1/Default Group/Dev" */
+	uuid, err := client.ResolveGroup(flag.Arg(0), *location, *acctAlias)
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+
+	grpEst, err := client.GetGroupEstimate(uuid, *acctAlias)
 	if err != nil {
 		exit.Fatalf("Failed to obtain hardware group estimate for %s: %s", flag.Arg(0), err)
 	}
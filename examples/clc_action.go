@@ -11,6 +11,7 @@ import (
 	"strings"
 	"path"
 	"flag"
+	"time"
 	"fmt"
 	"log"
 	"os"
@@ -19,10 +20,11 @@ import (
 func usage() {
 	fmt.Fprintf(os.Stderr, "usage:\n")
 	fmt.Fprintf(os.Stderr, "\t%s [options]      <action>  <Server-Name|Group-UUID>\n", path.Base(os.Args[0]))
-	fmt.Fprintf(os.Stderr, "\t%s -l <Location>  <action>  <Group-Name>\n\n", path.Base(os.Args[0]))
+	fmt.Fprintf(os.Stderr, "\t%s -l <Location>  <action>  <Group-Name>\n", path.Base(os.Args[0]))
+	fmt.Fprintf(os.Stderr, "\t%s [options]      <action>  <Location>/<Group>/<Sub-Group>/...\n\n", path.Base(os.Args[0]))
 
 	for _, r := range [][]string{
-		{ "show",     "show current status of server/group (group requires -l to be set)" },
+		{ "show",     "show current status of server/group (group requires -l or -all); see -watch, -format" },
 		{ "on",       "power on server/group (or resume from paused state)" },
 		{ "off",      "power off server/group" },
 		{ "shutdown", "OS-level shutdown followed by power-off for server/group" },
@@ -31,7 +33,8 @@ func usage() {
 		{ "reboot",   "reboot server/group" },
 		{ "snapshot", "snapshot server (not supported for groups)" },
 		{ "archive",  "archive the server/group" },
-		{ "delete",   "delete server/group (CAUTION)" },
+		{ "delete",   "delete server/group (CAUTION - requires confirmation)" },
+		{ "path",     "print the full path of server/group, e.g. WA1/Default Group/Web" },
 		{ "help",     "print this help screen" },
 	} {
 		fmt.Fprintf(os.Stderr, "\t%-10s %s\n", r[0], r[1])
@@ -53,7 +56,6 @@ func actionMap(server bool, client *clcv1.Client) map[string]func(string, string
 			"reboot":   client.RebootServer,
 			"shutdown": client.ShutdownServer,
 			"archive":  client.ArchiveServer,
-			"delete":   client.DeleteServer,
 			"snapshot": client.SnapshotServer,
 		}
 	}
@@ -66,13 +68,22 @@ func actionMap(server bool, client *clcv1.Client) map[string]func(string, string
 		"reboot":   client.RebootHardwareGroup,
 		"shutdown": client.ShutdownHardwareGroup,
 		"archive":  client.ArchiveHardwareGroup,
-		"delete":   client.DeleteHardwareGroup,
 	}
 }
 
 func main() {
 	var location  = flag.String("l", "", "Location to use for <Group-Name>")
 	var acctAlias = flag.String("a", "", "Account alias to use (to override default)")
+	var confirm   = flag.String("confirm",       "",     "Name of the server/group to delete (to skip the confirmation prompt)")
+	var protField = flag.String("protect-field", "",     "Name of the custom field that marks servers as protected from deletion")
+	var protList  = flag.String("protect-list",  "",     "File listing servers/groups that are protected from deletion")
+	var safety    = flag.String("safety",        "none", "Step to take before deleting: none, snapshot or archive")
+	var interval  = flag.Duration("watch",       0,      "Refresh 'show' output at this interval, highlighting changes")
+	var until     = flag.String("until",         "",     "With -watch, exit once all servers meet condition <field>=<value>, e.g. power=Started")
+	var format    = flag.String("format",        "text", "Format of group 'show' output: "+strings.Join(clcv1.GroupFormats, ", "))
+	var columns   = flag.String("cols",          "",     "Columns of -format tree, comma-separated: power, cpu, ip, cost")
+	var allLocs   = flag.Bool("all",             false,  "With 'show' and no argument: show the groups of all data centers")
+	var subAccts  = flag.Bool("sub",             false,  "With -all, also include the sub-accounts")
 	var serverAction bool
 	var action, where string
 
@@ -82,7 +93,7 @@ func main() {
 	if flag.NArg() == 2 {
 		action, where = flag.Arg(0), flag.Arg(1)
 	} else if flag.NArg() == 1 && flag.Arg(0) == "show" {
-		if *location == "" {
+		if *location == "" && !*allLocs {
 			exit.Errorf("Showing group details requires location (-l) argument.")
 		}
 		action = flag.Arg(0)
@@ -97,8 +108,15 @@ func main() {
 		exit.Fatalf("Login failed: %s", err)
 	}
 
+	/* A group path (e.g. WA1/Default Group/Web) also determines the location */
+	if strings.Contains(where, "/") {
+		node, err := client.LookupGroupNode(where, "", *acctAlias, false)
+		if err != nil {
+			exit.Fatal(err.Error())
+		}
+		where, *location = node.UUID, node.Location
 	/* If the first argument decodes as a hex value, assume it is a Hardware Group UUID */
-	if _, err := hex.DecodeString(where); err == nil {
+	} else if _, err := hex.DecodeString(where); err == nil {
 		serverAction = false
 	} else if utils.LooksLikeServerName(where) {
 		serverAction = true
@@ -119,23 +137,33 @@ func main() {
 
 	switch action {
 	case "show":
-		if serverAction {
+		if *allLocs && where == "" {
+			showForest(client, *acctAlias, *subAccts, *format, *columns)
+		} else if *interval > 0 {
+			watch(client, serverAction, where, *acctAlias, *location, *interval, *until)
+		} else if serverAction {
 			showServer(client, where, *acctAlias)
 		} else {
-			showGroup(client, where, *acctAlias, *location)
+			showGroup(client, where, *acctAlias, *location, *format, *columns)
 		}
 		os.Exit(0)
+	case "path":
+		showPath(client, serverAction, where, *acctAlias, *location)
+		os.Exit(0)
 	case "help":
 		usage()
 	}
 
 	/* Long-running commands that return a RequestID */
-	handler, ok := actionMap(serverAction, client)[action]
-	if !ok {
+	var reqID int
+
+	if action == "delete" {
+		reqID, err = safeDelete(client, serverAction, where, *confirm, *location, *acctAlias, *protField, *protList, *safety)
+	} else if handler, ok := actionMap(serverAction, client)[action]; !ok {
 		exit.Fatalf("Unsupported action %s", action)
+	} else {
+		reqID, err = handler(where, *acctAlias)
 	}
-
-	reqID, err := handler(where, *acctAlias)
 	if err != nil {
 		exit.Fatalf("Command %q failed: %s", action, err)
 	}
@@ -150,6 +178,47 @@ func main() {
 }
 
 
+// Delete server/group after confirmation, subject to protection rules.
+// @client:    authenticated CLCv1 Client
+// @server:    whether @where refers to a server (true) or a hardware group UUID (false)
+// @where:     server name or hardware group UUID
+// @confirm:   name of the resource to delete; prompt for it if empty
+// @location:  data centre location (needed to resolve group contents)
+// @acctAlias: account alias to use (leave blank to use default)
+// @protField: name of the custom field that marks protected servers (optional)
+// @protList:  path of the local protect-list (optional)
+// @safety:    safety step to take before deleting (none, snapshot or archive)
+func safeDelete(client *clcv1.Client, server bool, where, confirm, location, acctAlias,
+		protField, protList, safety string) (reqID int, err error) {
+	var guard = clcv1.DeleteGuard{ ProtectField: protField }
+
+	if guard.Safety, err = clcv1.ParseDeleteSafety(safety); err != nil {
+		return 0, err
+	} else if protList != "" {
+		if err = guard.LoadProtectList(protList); err != nil {
+			return 0, err
+		}
+	}
+
+	if confirm == "" {
+		var prompt = fmt.Sprintf("Type %q to confirm deletion", where)
+
+		if !server {
+			prompt = fmt.Sprintf("Type the name or UUID (%s) of the group to confirm deletion", where)
+		}
+		if confirm, err = utils.PromptInput(prompt); err != nil {
+			return 0, err
+		}
+	}
+
+	if server {
+		return client.SafeDeleteServer(&guard, where, confirm, acctAlias)
+	} else if location == "" {
+		return 0, fmt.Errorf("Deleting a group requires the location (-l) argument.")
+	}
+	return client.SafeDeleteHardwareGroup(&guard, where, confirm, location, acctAlias)
+}
+
 // Show server details
 // @client:    authenticated CLCv1 Client
 // @servname:  server name
@@ -191,7 +260,7 @@ func showServer(client *clcv1.Client, servname, acctAlias string) {
 	}
 
 	table.Append([]string{
-		server.Name, server.PowerState, server.Status,
+		server.Name, server.PowerState.String(), server.Status.String(),
 		fmt.Sprint(server.OperatingSystem),
 		server.Description,
 		fmt.Sprint(server.Cpu), fmt.Sprintf("%dGB", server.TotalDiskSpaceGB),
@@ -206,7 +275,9 @@ func showServer(client *clcv1.Client, servname, acctAlias string) {
 // @uuid:      hardware group UUID to use
 // @acctAlias: account alias to use (leave blank to use default)
 // @location:  data centre location (needed to resolve @uuid)
-func showGroup(client *clcv1.Client, uuid, acctAlias, location string) {
+// @format:    output format (see clcv1.GroupFormats)
+// @columns:   comma-separated columns of the "tree" format (see clcv1.ParseTreeColumns)
+func showGroup(client *clcv1.Client, uuid, acctAlias, location, format, columns string) {
 	if location == "" {
 		exit.Errorf("Location is required in order to show the group hierarchy starting at %s", uuid)
 	}
@@ -223,5 +294,143 @@ func showGroup(client *clcv1.Client, uuid, acctAlias, location string) {
 			exit.Fatalf("Failed to look up UUID %s at %s", uuid, location)
 		}
 	}
-	clcv1.PrintGroupHierarchy(start, "")
+	renderGroups(client, start, acctAlias, format, columns)
+}
+
+// Show the group hierarchies of all data centers
+// @client:    authenticated CLCv1 Client
+// @acctAlias: account alias to use (leave blank to use default)
+// @subAccts:  whether to include the sub-accounts of @acctAlias
+// @format:    output format (see clcv1.GroupFormats)
+// @columns:   comma-separated columns of the "tree" format (see clcv1.ParseTreeColumns)
+func showForest(client *clcv1.Client, acctAlias string, subAccts bool, format, columns string) {
+	root, err := client.GetGroupForest(acctAlias, clcv1.ForestOptions{ Servers: true, SubAccounts: subAccts })
+	if root == nil {
+		exit.Fatal(err.Error())
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", err)
+	}
+	renderGroups(client, root, acctAlias, format, columns)
+}
+
+// Render the groups starting at @start to stdout
+// @client:    authenticated CLCv1 Client
+// @start:     root of the hierarchy to show
+// @acctAlias: account alias to use (leave blank to use default)
+// @format:    output format (see clcv1.GroupFormats)
+// @columns:   comma-separated columns of the "tree" format (see clcv1.ParseTreeColumns)
+func renderGroups(client *clcv1.Client, start *clcv1.GroupNode, acctAlias, format, columns string) {
+	var opts clcv1.TreeOptions
+	var err  error
+
+	if opts.Columns, err = clcv1.ParseTreeColumns(columns); err != nil {
+		exit.Fatal(err.Error())
+	}
+
+	for _, col := range opts.Columns {
+		if col == clcv1.TreeCost {
+			if opts.Costs, err = client.GetServerCosts(start.AllServers(false), acctAlias, 4); err != nil {
+				fmt.Fprintf(os.Stderr, "WARNING: %s\n", err)
+			}
+		}
+	}
+	if err := clcv1.RenderGroupHierarchy(os.Stdout, start, format, opts); err != nil {
+		exit.Fatal(err.Error())
+	}
+}
+
+// Print the full path of a server or group
+// @client:    authenticated CLCv1 Client
+// @server:    whether @where refers to a server (true) or a hardware group UUID (false)
+// @where:     server name or hardware group UUID
+// @acctAlias: account alias to use (leave blank to use default)
+// @location:  data centre location (needed to resolve @where if it is a group)
+func showPath(client *clcv1.Client, server bool, where, acctAlias, location string) {
+	var p   string
+	var err error
+
+	if server {
+		p, err = client.ServerPath(where, acctAlias)
+	} else if location == "" {
+		exit.Errorf("Location is required in order to resolve the path of group %s", where)
+	} else {
+		p, err = client.GroupPath(where, location, acctAlias)
+	}
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+	fmt.Println(p)
+}
+
+// Show the status of a server or of the servers in a group, refreshing at @interval.
+// @client:    authenticated CLCv1 Client
+// @server:    whether @where refers to a server (true) or a hardware group UUID (false)
+// @where:     server name or hardware group UUID
+// @acctAlias: account alias to use (leave blank to use default)
+// @location:  data centre location (needed to resolve group contents)
+// @until:     optional condition to exit on (see clcv1.ParseWatchCondition)
+func watch(client *clcv1.Client, server bool, where, acctAlias, location string, interval time.Duration, until string) {
+	var watcher *clcv1.Watcher
+	var cond    clcv1.WatchCondition
+	var err     error
+
+	if until != "" {
+		if cond, err = clcv1.ParseWatchCondition(until); err != nil {
+			exit.Fatal(err.Error())
+		}
+	}
+
+	if server {
+		watcher = client.NewServerWatcher([]string{ where }, acctAlias)
+	} else if location == "" {
+		exit.Errorf("Location is required in order to watch group %s", where)
+	} else {
+		watcher = client.NewGroupWatcher(where, location, acctAlias)
+	}
+
+	err = watcher.Run(interval, cond, func(u *clcv1.WatchUpdate) {
+		/* Redraw in place: move cursor to top left and clear the screen */
+		fmt.Printf("\033[H\033[2J")
+		fmt.Printf("Every %s: %s   %s\n\n", interval, where, u.When.Format(time.Stamp))
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetAutoFormatHeaders(false)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAutoWrapText(false)
+
+		table.SetHeader([]string{ "Name", "Power", "Status", "Maintenance", "CPU", "Memory", "Disk", "IP" })
+
+		for _, s := range u.Servers {
+			/* Highlight (reverse video) fields that changed since the last refresh */
+			hl := func(field, value string) string {
+				if u.Changed(s.Name, field) {
+					return "\033[7m" + value + "\033[0m"
+				}
+				return value
+			}
+			table.Append([]string{
+				s.Name,
+				hl("PowerState", s.PowerState.String()),
+				hl("Status", s.Status.String()),
+				hl("InMaintenanceMode", fmt.Sprint(s.InMaintenanceMode)),
+				hl("Cpu", fmt.Sprint(s.Cpu)),
+				hl("MemoryGB", fmt.Sprintf("%dGB", s.MemoryGB)),
+				hl("TotalDiskSpaceGB", fmt.Sprintf("%dGB", s.TotalDiskSpaceGB)),
+				hl("IPAddress", s.IPAddress),
+			})
+		}
+		table.Render()
+
+		for _, name := range u.Added {
+			fmt.Printf("+ %s\n", name)
+		}
+		for _, name := range u.Removed {
+			fmt.Printf("- %s\n", name)
+		}
+	})
+	if err != nil {
+		exit.Fatalf("Failed to refresh status: %s", err)
+	} else if until != "" {
+		fmt.Printf("Condition %q met.\n", until)
+	}
 }
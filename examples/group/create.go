@@ -4,7 +4,6 @@
 package main
 
 import (
-	"encoding/hex"
 	"github.com/grrtrr/clcv1"
 	"github.com/grrtrr/exit"
 	"path"
@@ -15,8 +14,7 @@ import (
 )
 
 func main() {
-	var parentUUID string
-	var parentGroup = flag.String("g", "", "UUID or Name (if unique and -l present) of the parent Hardware Group")
+	var parentGroup = flag.String("g", "", "UUID, path or Name (if unique and -l present) of the parent Hardware Group")
 	var location    = flag.String("l", "", "Data centre location to use for resolving -g <Group-Name>")
 	var desc        = flag.String("t", "", "Textual description of the new group")
 	var acctAlias   = flag.String("a", "", "Account alias to use")
@@ -31,13 +29,6 @@ func main() {
 		os.Exit(1)
 	}
 
-	/* parentGroup may be hex uuid or group name */
-	if _, err := hex.DecodeString(*parentGroup); err == nil {
-		parentUUID = *parentGroup
-	} else if *location == "" {
-		exit.Errorf("Using -g <Group-Name> requires -l <Location> to be set")
-	}
-
 	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
 	if err != nil {
 		exit.Fatal(err.Error())
@@ -45,14 +36,10 @@ func main() {
 		exit.Fatalf("Login failed: %s", err)
 	}
 
-	if parentUUID == "" {
-		if group, err := client.GetGroupByName(*parentGroup, *location, *acctAlias); err != nil {
-			exit.Errorf("Failed to resolve group name %q: %s", *parentGroup, err)
-		} else if group == nil {
-			exit.Errorf("No group named %q was found on %s", *parentGroup, *location)
-		} else {
-			parentUUID = group.UUID
-		}
+	/* parentGroup may be a UUID, group path or group name */
+	parentUUID, err := client.ResolveGroup(*parentGroup, *location, *acctAlias)
+	if err != nil {
+		exit.Fatal(err.Error())
 	}
 
 	g, err := client.CreateHardwareGroup(*acctAlias, parentUUID, flag.Arg(0), *desc)
@@ -17,7 +17,7 @@ func main() {
 	var acct = flag.String("a", "", "Account alias to use")
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "usage: %s [options]  <HW Group UUID>\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "usage: %s [options]  <HW Group UUID|Path>\n", path.Base(os.Args[0]))
 		flag.PrintDefaults()
 	}
 	flag.Parse()
@@ -33,7 +33,13 @@ func main() {
 		exit.Fatalf("Login failed: %s", err)
 	}
 
-	reqId, err := client.DeleteHardwareGroup(flag.Arg(0), *acct)
+	/* The group may also be given by path, e.g. "WA1/Default Group/Dev" */
+	uuid, err := client.ResolveGroup(flag.Arg(0), "", *acct)
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+
+	reqId, err := client.DeleteHardwareGroup(uuid, *acct)
 	if err != nil {
 		exit.Fatalf("Failed to delete hardware group: %s", err)
 	}
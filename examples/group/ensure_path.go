@@ -0,0 +1,47 @@
+/*
+ * Make sure a hardware group path exists, creating missing groups along the way.
+ */
+package main
+
+import (
+	"github.com/grrtrr/clcv1"
+	"github.com/grrtrr/exit"
+	"path"
+	"flag"
+	"log"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var desc      = flag.String("t", "", "Textual description of newly created groups")
+	var acctAlias = flag.String("a", "", "Account alias to use")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [options]  <Location>/<Group>/<Sub-Group>/...\n", path.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if err := client.Logon("", ""); err != nil {
+		exit.Fatalf("Login failed: %s", err)
+	}
+
+	leaf, created, err := client.EnsureGroupPath(flag.Arg(0), *desc, *acctAlias)
+	for _, g := range created {
+		fmt.Printf("Created %s (%s)\n", g.Path(), g.UUID)
+	}
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if len(created) == 0 {
+		fmt.Printf("%s already exists.\n", leaf.Path())
+	}
+	fmt.Println("UUID:", leaf.UUID)
+}
@@ -18,7 +18,7 @@ func main() {
 	var maintenance = flag.Bool("m", false, "Turn maintenance mode on (-m) or off (-m=false)")
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "usage: %s [options]  <HW Group UUID>\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "usage: %s [options]  <HW Group UUID|Path>\n", path.Base(os.Args[0]))
 		flag.PrintDefaults()
 	}
 
@@ -35,7 +35,13 @@ func main() {
 		exit.Fatalf("Login failed: %s", err)
 	}
 
-	reqId, err := client.HardwareGroupMaintenance(*maintenance, flag.Arg(0), *acctAlias)
+	/* The group may also be given by path, e.g. "WA1/Default Group/Dev" */
+	uuid, err := client.ResolveGroup(flag.Arg(0), "", *acctAlias)
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+
+	reqId, err := client.HardwareGroupMaintenance(*maintenance, uuid, *acctAlias)
 	if err != nil {
 		exit.Fatalf("Failed to modify maintenance mode: %s", err)
 	}
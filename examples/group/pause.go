@@ -17,7 +17,7 @@ func main() {
 	var acctAlias = flag.String("a", "", "Account alias to use")
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "usage: %s [options]  <HW Group UUID>\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "usage: %s [options]  <HW Group UUID|Path>\n", path.Base(os.Args[0]))
 		flag.PrintDefaults()
 	}
 
@@ -34,7 +34,13 @@ func main() {
 		exit.Fatalf("Login failed: %s", err)
 	}
 
-	reqId, err := client.PauseHardwareGroup(flag.Arg(0), *acctAlias)
+	/* The group may also be given by path, e.g. "WA1/Default Group/Dev" */
+	uuid, err := client.ResolveGroup(flag.Arg(0), "", *acctAlias)
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+
+	reqId, err := client.PauseHardwareGroup(uuid, *acctAlias)
 	if err != nil {
 		exit.Fatalf("Failed to pause hardware group: %s", err)
 	}
@@ -15,9 +15,10 @@ import (
 
 func main() {
 	var acctAlias  = flag.String("a",      "", "Account alias to use")
-	var parentUuid = flag.String("parent", "", "Parent group UUID to restore the group into")
+	var location   = flag.String("l",      "", "Data centre location (needed to resolve group names)")
+	var parentUuid = flag.String("parent", "", "Parent group (UUID, name or path) to restore the group into")
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "usage: %s [options]  <HW Group UUID>\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "usage: %s [options]  <HW Group UUID|Name|Path>\n", path.Base(os.Args[0]))
 		flag.PrintDefaults()
 	}
 
@@ -34,7 +35,17 @@ func main() {
 		exit.Fatalf("Login failed: %s", err)
 	}
 
-	reqId, err := client.RestoreHardwareGroup(flag.Arg(0), *parentUuid, *acctAlias)
+	/* Both groups may also be given by name or path, e.g. "WA1/Default Group/Dev" */
+	uuid, err := client.ResolveGroup(flag.Arg(0), *location, *acctAlias)
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+	parent, err := client.ResolveGroup(*parentUuid, *location, *acctAlias)
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+
+	reqId, err := client.RestoreHardwareGroup(uuid, parent, *acctAlias)
 	if err != nil {
 		exit.Fatalf("Failed to restore Hardware Group %s: %s", flag.Arg(0), err)
 	}
@@ -0,0 +1,93 @@
+/*
+ * Per-group rollup statistics (server counts, power states, CPU, memory, disk, OS).
+ */
+package main
+
+import (
+	"github.com/olekukonko/tablewriter"
+	"github.com/grrtrr/clcv1"
+	"github.com/grrtrr/exit"
+	"strings"
+	"sort"
+	"path"
+	"flag"
+	"log"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var location  = flag.String("l",       "",    "Data centre location (not needed if <group> is a path)")
+	var acctAlias = flag.String("a",       "",    "Account alias to use")
+	var maxDepth  = flag.Int("d",          -1,    "Only list groups up to this depth below the start group (-1 = all)")
+	var started   = flag.Bool("started",   false, "Only count servers that are powered on")
+	var osStats   = flag.Bool("os",        false, "Also print the breakdown by operating system")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [options]  [<HW Group UUID|Name|Path>]\n", path.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 || (flag.NArg() == 0 && *location == "") {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if err := client.Logon("", ""); err != nil {
+		exit.Fatalf("Login failed: %s", err)
+	}
+
+	start, err := client.LookupGroupNode(flag.Arg(0), *location, *acctAlias, true)
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+
+	if *started {
+		start = start.FilterServers(func(s *clcv1.Server) bool { return s.PowerState == clcv1.PowerStarted })
+		if start == nil {
+			fmt.Println("No servers are powered on.")
+			return
+		}
+	}
+	stats := start.Rollup()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoFormatHeaders(false)
+	table.SetAlignment(tablewriter.ALIGN_RIGHT)
+	table.SetAutoWrapText(false)
+
+	table.SetHeader([]string{ "Group", "Groups", "Servers", "Started", "Stopped", "Paused", "CPU", "Memory", "Disk" })
+
+	start.Walk(clcv1.PreOrder, func(g *clcv1.GroupNode, depth int) clcv1.WalkAction {
+		st := stats[g]
+		table.Append([]string{
+			strings.Repeat("  ", depth) + g.Name,
+			fmt.Sprint(st.Groups), fmt.Sprint(st.Servers),
+			fmt.Sprint(st.Started), fmt.Sprint(st.Stopped), fmt.Sprint(st.Paused),
+			fmt.Sprint(st.Cpu), fmt.Sprintf("%dGB", st.MemoryGB), fmt.Sprintf("%dGB", st.DiskGB),
+		})
+		if depth == *maxDepth {
+			return clcv1.WalkSkip
+		}
+		return clcv1.WalkContinue
+	})
+	table.Render()
+
+	if *osStats {
+		var total = stats[start]
+		var oses  []clcv1.OperatingSystem
+
+		for o := range total.OS {
+			oses = append(oses, o)
+		}
+		sort.Slice(oses, func(i, j int) bool { return total.OS[oses[i]] > total.OS[oses[j]] })
+
+		fmt.Printf("\nOperating systems below %s:\n", start.Path())
+		for _, o := range oses {
+			fmt.Printf("%6d  %s\n", total.OS[o], o)
+		}
+	}
+}
@@ -18,7 +18,7 @@ import (
 func main() {
 	var acctAlias = flag.String("a", "", "Account alias of the account that owns the servers")
 	var location  = flag.String("l", "", "The data center location")
-	var hwGrpUUID = flag.String("u", "", "UUID of the Hardware Group")
+	var hwGrpUUID = flag.String("u", "", "UUID or path (e.g. WA1/Default Group/Web) of the Hardware Group")
 	var simple    = flag.Bool("simple", false, "Use simple (debugging) output format")
 
 	flag.Usage = func() {
@@ -41,6 +41,12 @@ func main() {
 		exit.Fatalf("Login failed: %s", err)
 	}
 
+	if *hwGrpUUID != "" {
+		if *hwGrpUUID, err = client.ResolveGroup(*hwGrpUUID, *location, *acctAlias); err != nil {
+			exit.Fatal(err.Error())
+		}
+	}
+
 	servers, err := client.GetAllServers(*acctAlias, *hwGrpUUID, *location)
 	if err != nil {
 		exit.Fatalf("Failed to list all servers: %s", err)
@@ -68,7 +74,7 @@ func main() {
 				s.Name, s.Description,
 				fmt.Sprint(s.Cpu), fmt.Sprint(s.DiskCount), fmt.Sprint(s.TotalDiskSpaceGB),
 				fmt.Sprintf("%25.25s", s.OperatingSystem), s.IPAddress,
-				s.PowerState, fmt.Sprintf("%12.12s", s.ModifiedBy),
+				s.PowerState.String(), fmt.Sprintf("%12.12s", s.ModifiedBy),
 				s.DateModified.Format("Jan _2/06 15:04"),
 			})
 		}
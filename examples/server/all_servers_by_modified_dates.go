@@ -16,7 +16,7 @@ import (
 
 func main() {
 	var acctAlias = flag.String("a", "", "Account alias of the account that owns the servers")
-	var hwGrpUUID = flag.String("u", "", "UUID of the Hardware Group")
+	var hwGrpUUID = flag.String("u", "", "UUID or path (e.g. WA1/Default Group/Web) of the Hardware Group")
 	var location  = flag.String("l", "", "The data center location")
 	var beginDate = flag.String("b", "", "Only list servers modified later than this date (defaults to yesterday)")
 	var endDate   = flag.String("e", "", "Only list servers modified earlier than this date (defaults to now)")
@@ -31,6 +31,12 @@ func main() {
 		exit.Fatalf("Login failed: %s", err)
 	}
 
+	if *hwGrpUUID != "" {
+		if *hwGrpUUID, err = client.ResolveGroup(*hwGrpUUID, *location, *acctAlias); err != nil {
+			exit.Fatal(err.Error())
+		}
+	}
+
 	servers, err := client.GetAllServersByModifiedDates(*acctAlias, *hwGrpUUID, *location, *beginDate, *endDate)
 	if err != nil {
 		exit.Fatalf("Failed to list all servers: %s", err)
@@ -58,7 +64,7 @@ func main() {
 				s.Name, s.Description,
 				fmt.Sprint(s.Cpu), fmt.Sprint(s.DiskCount), fmt.Sprint(s.TotalDiskSpaceGB),
 				fmt.Sprintf("%25.25s", s.OperatingSystem), s.IPAddress,
-				s.PowerState, fmt.Sprintf("%12.12s", s.ModifiedBy),
+				s.PowerState.String(), fmt.Sprintf("%12.12s", s.ModifiedBy),
 				s.DateModified.Format("Jan _2/06 15:04"),
 			})
 		}
@@ -17,10 +17,11 @@ import (
 
 func main() {
 	var acctAlias = flag.String("a", "", "Account alias of the account that owns the servers")
+	var location  = flag.String("l", "", "Data centre location (needed to resolve group names)")
 	var simple    = flag.Bool("simple", false, "Use simple (debugging) output format")
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "usage: %s [options]  <HW Group UUID>\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "usage: %s [options]  <HW Group UUID|Name|Path>\n", path.Base(os.Args[0]))
 		flag.PrintDefaults()
 	}
 
@@ -37,7 +38,13 @@ func main() {
 		exit.Fatalf("Login failed: %s", err)
 	}
 
-	servers, err := client.GetServers(flag.Arg(0), *acctAlias)
+	/* The group may also be given by name or path, e.g. "WA1/Default Group/Dev" */
+	uuid, err := client.ResolveGroup(flag.Arg(0), *location, *acctAlias)
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+
+	servers, err := client.GetServers(uuid, *acctAlias)
 	if err != nil {
 		exit.Fatalf("Failed to list all servers: %s", err)
 	}
@@ -64,7 +71,7 @@ func main() {
 				s.Name, s.Description,
 				fmt.Sprint(s.Cpu), fmt.Sprint(s.DiskCount), fmt.Sprint(s.TotalDiskSpaceGB),
 				fmt.Sprintf("%25.25s", s.OperatingSystem), s.IPAddress,
-				s.PowerState, fmt.Sprintf("%12.12s", s.ModifiedBy),
+				s.PowerState.String(), fmt.Sprintf("%12.12s", s.ModifiedBy),
 				s.DateModified.Format("Jan _2/06 15:04"),
 			})
 		}
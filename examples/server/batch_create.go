@@ -0,0 +1,107 @@
+/*
+ * Create a batch of servers from a JSON manifest, e.g. for load tests.
+ *
+ * The manifest is a JSON array of CreateServer requests, each with an optional Count:
+ *   [ { "LocationAlias": "WA1", "Template": "UBUNTU-14-64-TEMPLATE", "Alias": "LOAD",
+ *       "HardwareGroupUUID": "...", "ServerType": "Standard", "ServiceLevel": "Standard",
+ *       "Cpu": 2, "MemoryGB": 4, "Network": "...", "Count": 20 } ]
+ */
+package main
+
+import (
+	"github.com/olekukonko/tablewriter"
+	"github.com/grrtrr/clcv1"
+	"github.com/grrtrr/exit"
+	"strings"
+	"path"
+	"flag"
+	"log"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var count    = flag.Int("n",          0, "Override the count of each spec in the manifest")
+	var parallel = flag.Int("p",          5, "Maximum number of CreateServer requests in flight")
+	var atomic   = flag.Bool("atomic", false, "All-or-nothing: delete the created servers if any creation fails")
+	var dryRun   = flag.Bool("dry",    false, "Only validate the manifest")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [options]  <manifest.json>\n", path.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	specs, err := clcv1.LoadBatchManifest(flag.Arg(0))
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+
+	var total int
+	for i := range specs {
+		if *count > 0 {
+			specs[i].Count = *count
+		} else if specs[i].Count == 0 {
+			specs[i].Count = 1
+		}
+		total += specs[i].Count
+	}
+
+	for i := range specs {
+		if err := specs[i].Validate(); err != nil {
+			exit.Fatalf("Spec #%d is invalid: %s", i + 1, err)
+		}
+	}
+	if *dryRun {
+		fmt.Printf("Manifest OK: %d spec(s), %d server(s).\n", len(specs), total)
+		return
+	}
+
+	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if err := client.Logon("", ""); err != nil {
+		exit.Fatalf("Login failed: %s", err)
+	}
+
+	fmt.Printf("Creating %d server(s) ...\n", total)
+	report, err := client.BatchCreateServers(specs, clcv1.BatchOptions{
+		Parallel:     *parallel,
+		AllOrNothing: *atomic,
+	})
+	if err != nil {
+		exit.Fatalf("Failed to start batch: %s", err)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoFormatHeaders(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(true)
+
+	table.SetHeader([]string{ "Spec", "Request ID", "Server(s)", "Error" })
+
+	for _, r := range report.Results {
+		var errMsg string
+
+		if r.Err != nil {
+			errMsg = r.Err.Error()
+		} else if r.Skipped {
+			errMsg = "not submitted (batch failed)"
+		}
+		table.Append([]string{ fmt.Sprint(r.Spec + 1), fmt.Sprint(r.RequestID), strings.Join(r.Servers, ", "), errMsg })
+	}
+	table.Render()
+
+	fmt.Println(report)
+	for _, e := range report.RollbackErrs {
+		fmt.Fprintf(os.Stderr, "Rollback failed: %s\n", e)
+	}
+	if report.Failed() > 0 {
+		os.Exit(1)
+	}
+}
@@ -16,7 +16,7 @@ import (
 func main() {
 	var acctAlias = flag.String("a",   "", "Account alias to use")
 	var oldPasswd = flag.String("old", "", "The existing password (for authentication)")
-	var newPasswd = flag.String("new", "", "The new password to apply")
+	var newPasswd = flag.String("new", "", "The new password to apply (generated if not set)")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "usage: %s [options]  <server-name>\n", path.Base(os.Args[0]))
@@ -25,7 +25,7 @@ func main() {
 
 	flag.Parse()
 
-	if flag.NArg() != 1 || *newPasswd == "" {
+	if flag.NArg() != 1 {
 		flag.Usage()
 		os.Exit(1)
 	}
@@ -46,6 +46,20 @@ func main() {
 		*oldPasswd = creds.Password
 	}
 
+	server, err := client.GetServer(flag.Arg(0), *acctAlias)
+	if err != nil {
+		exit.Fatalf("Failed to look up %s: %s", flag.Arg(0), err)
+	}
+
+	policy := clcv1.PasswordPolicyFor(server.OperatingSystem)
+	if *newPasswd == "" {
+		if *newPasswd, err = policy.Generate(0); err != nil {
+			exit.Fatalf("Failed to generate password: %s", err)
+		}
+	} else if err := policy.Check(*newPasswd); err != nil {
+		exit.Fatalf("New password does not meet the %s password policy: %s", policy.Name, err)
+	}
+
 	fmt.Printf("Changing password on %s from <%v> to <%v> ...\n", flag.Arg(0), *oldPasswd, *newPasswd)
 
 	err = client.ServerChangePassword(flag.Arg(0), *acctAlias, *oldPasswd, *newPasswd)
@@ -0,0 +1,78 @@
+/*
+ * Report server changes (create/delete and field-level modifications), resuming from a saved checkpoint.
+ */
+package main
+
+import (
+	"github.com/grrtrr/clcv1"
+	"github.com/grrtrr/exit"
+	"path"
+	"flag"
+	"time"
+	"log"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var acctAlias = flag.String("a",     "",                 "Account alias of the account that owns the servers")
+	var hwGrpUUID = flag.String("u",     "",                 "UUID or path (e.g. WA1/Default Group/Web) of the Hardware Group")
+	var location  = flag.String("l",     "",                 "The data center location")
+	var stateFile = flag.String("state", "clc_changes.json", "File to keep the checkpoint in (empty to disable)")
+	var interval  = flag.Duration("i",   0,                  "Poll interval (0 for one-off)")
+	var fullSync  = flag.Int("full",     10,                 "Number of polls between full listings, which detect deleted servers")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [options]\n", path.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if err := client.Logon("", ""); err != nil {
+		exit.Fatalf("Login failed: %s", err)
+	}
+
+	if *hwGrpUUID != "" {
+		if *hwGrpUUID, err = client.ResolveGroup(*hwGrpUUID, *location, *acctAlias); err != nil {
+			exit.Fatal(err.Error())
+		}
+	}
+
+	feed, err := client.NewServerChangeFeed(*acctAlias, *hwGrpUUID, *location, *stateFile)
+	if err != nil {
+		exit.Fatalf("Failed to set up change feed: %s", err)
+	}
+	feed.FullSyncInterval = *fullSync
+
+	if feed.Checkpoint().IsZero() {
+		fmt.Println("No checkpoint found - recording current state as baseline.")
+	} else {
+		fmt.Printf("Resuming from checkpoint %s.\n", feed.Checkpoint().Local().Format(time.Stamp))
+	}
+
+	for {
+		changes, err := feed.Poll()
+		if err != nil {
+			exit.Fatalf("Failed to poll for server changes: %s", err)
+		}
+
+		for _, c := range changes {
+			fmt.Printf("%s  %-8s %s", c.When.Local().Format(time.Stamp), c.Type, c.Name)
+			if c.ModifiedBy != "" {
+				fmt.Printf(" (by %s)", c.ModifiedBy)
+			}
+			fmt.Println()
+			for _, f := range c.Fields {
+				fmt.Printf("\t%-20s %s -> %s\n", f.Field, f.Old, f.New)
+			}
+		}
+
+		if *interval == 0 {
+			break
+		}
+		time.Sleep(*interval)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ * Create one or more servers with the same configuration as an existing server.
+ */
+package main
+
+import (
+	"github.com/grrtrr/clcv1/utils"
+	"github.com/grrtrr/clcv1"
+	"github.com/grrtrr/exit"
+	"path"
+	"flag"
+	"log"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var acctAlias = flag.String("a",   "",    "Account alias to use")
+	var seed      = flag.String("s",   "",    "Override the seed for the server name (max 6 characters)")
+	var hwGroup   = flag.String("g",   "",    "Override the HW group (UUID, name if unique, or path)")
+	var template  = flag.String("t",   "",    "Override the template")
+	var net       = flag.String("net", "",    "Override the network")
+	var count     = flag.Int("n",      1,     "Number of servers to create")
+	var dryRun    = flag.Bool("dry",   false, "Only print the creation request")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [options]  <server-name>\n", path.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+	if flag.NArg() != 1 || *count < 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if err := client.Logon("", ""); err != nil {
+		exit.Fatalf("Login failed: %s", err)
+	}
+
+	req, err := client.CloneServerSpec(flag.Arg(0), *acctAlias)
+	if err != nil {
+		exit.Fatalf("Failed to build a clone of %s: %s", flag.Arg(0), err)
+	}
+
+	if *seed != "" {
+		req.Alias = *seed
+	}
+	if *template != "" {
+		req.Template = *template
+	}
+	if *net != "" {
+		req.Network = *net
+	}
+
+	/* hwGroup may be a UUID, group name or group path; if empty, keep the group of the original server */
+	if *hwGroup != "" {
+		if req.HardwareGroupUUID, err = client.ResolveGroup(*hwGroup, req.LocationAlias, *acctAlias); err != nil {
+			exit.Fatal(err.Error())
+		}
+	}
+
+	if *dryRun {
+		utils.PrintStruct(req)
+		os.Exit(0)
+	}
+
+	if err := client.ValidateCreateServer(req); err != nil {
+		exit.Fatalf("Invalid clone request: %s", err)
+	}
+
+	for i := 0; i < *count; i++ {
+		reqId, err := client.CreateServer(req)
+		if err != nil {
+			exit.Fatalf("Failed to create server %d/%d: %s", i+1, *count, err)
+		}
+		fmt.Printf("Request ID for server creation %d/%d: %d\n", i+1, *count, reqId)
+	}
+}
@@ -4,6 +4,7 @@
 package main
 
 import (
+	"github.com/grrtrr/clcv1/utils"
 	"github.com/grrtrr/clcv1"
 	"github.com/grrtrr/exit"
 	"path"
@@ -14,11 +15,14 @@ import (
 )
 
 func main() {
-	var hwUUID    = flag.String("u", "", "Hardware UUID of the HW group this server belongs to")
+	var hwUUID    = flag.String("u", "", "UUID, name or path of the HW group to move this server to")
 	var acctAlias = flag.String("a", "", "Account alias to use")
 	var memGB     = flag.Int("mem",   0, "Amount of memory in GB")
 	var numCpu    = flag.Int("cpu",   0, "Number of Cpus to use")
 	var extraDrv  = flag.Int("drv",   0, "Extra storage (in GB) to add to server")
+	var fields    = utils.KeyValueFlag{}
+
+	flag.Var(fields, "f", "Custom field <name>=<value> to set (may be repeated)")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "usage: %s [options]  <server-name>\n", path.Base(os.Args[0]))
@@ -38,51 +42,39 @@ func main() {
 		exit.Fatalf("Login failed: %s", err)
 	}
 
-	req := clcv1.ConfigureServerReq{ Name: flag.Arg(0) }
-	if *acctAlias != "" {
-		req.AccountAlias = *acctAlias
-	}
-
-	if *numCpu == 0 || *memGB == 0 || *hwUUID == "" {
-		fmt.Printf("Fetching details of %s for re-configuration ...\n", flag.Arg(0))
-
-		server, err := client.GetServer(flag.Arg(0), *acctAlias)
-		if err != nil {
-			exit.Fatalf("Failed to retrieve details of server %q: %s", flag.Arg(0), err)
-		}
-		req.HardwareGroupUUID = server.HardwareGroupUUID
-		req.Cpu               = server.Cpu
-		req.MemoryGB          = server.MemoryGB
-	}
-
+	var opts []clcv1.UpdateOption
 	if *hwUUID != "" {
-		if req.HardwareGroupUUID != "" && *hwUUID != req.HardwareGroupUUID {
-			fmt.Printf("Moving server from %s -> %s\n", req.HardwareGroupUUID, *hwUUID)
+		/* A group name is looked up in the data center of the server. */
+		location := utils.ExtractLocationFromServerName(flag.Arg(0))
+		uuid, err := client.ResolveGroup(*hwUUID, location, *acctAlias)
+		if err != nil {
+			exit.Fatal(err.Error())
 		}
-		req.HardwareGroupUUID = *hwUUID
+		opts = append(opts, clcv1.WithGroup(uuid))
 	}
 	if *memGB != 0 {
-		if req.MemoryGB != 0 && *memGB != req.MemoryGB {
-			fmt.Printf("Changing memory from %d to %d GB\n", req.MemoryGB, *memGB)
-		}
-		req.MemoryGB = *memGB
+		opts = append(opts, clcv1.WithMemoryGB(*memGB))
 	}
 	if *numCpu != 0 {
-		if req.Cpu != 0 && *numCpu != req.Cpu {
-			fmt.Printf("Changing Cpu number from %d to %d\n", req.Cpu, *numCpu)
-		}
-		req.Cpu = *numCpu
+		opts = append(opts, clcv1.WithCpu(*numCpu))
 	}
 	if *extraDrv != 0 {
-		fmt.Printf("Adding %d GB extra storage\n", *extraDrv)
-		req.AdditionalStorageGB = *extraDrv
+		opts = append(opts, clcv1.WithAdditionalStorageGB(*extraDrv))
+	}
+	if len(fields) > 0 {
+		opts = append(opts, clcv1.WithCustomFields(fields))
 	}
-	// FIXME: not addressing CustomFields in this revision
 
-	reqId, err := client.ConfigureServer(&req)
+	changes, reqId, err := client.UpdateServer(flag.Arg(0), *acctAlias, opts...)
 	if err != nil {
 		exit.Fatalf("Failed to configure server %s: %s", flag.Arg(0), err)
+	} else if len(changes) == 0 {
+		fmt.Printf("%s already has the requested configuration.\n", flag.Arg(0))
+		return
 	}
 
+	for _, c := range changes {
+		fmt.Printf("%-20s %s -> %s\n", c.Field, c.Old, c.New)
+	}
 	fmt.Println("Request ID for server configuration:", reqId)
 }
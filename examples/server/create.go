@@ -4,9 +4,9 @@
 package main
 
 import (
+	"github.com/grrtrr/clcv1/utils"
 	"github.com/grrtrr/clcv1"
 	"github.com/grrtrr/exit"
-	"encoding/hex"
 	"path"
 	"flag"
 	"log"
@@ -15,7 +15,7 @@ import (
 )
 
 func main() {
-	var hwGroup    = flag.String("g", "",     "UUID or name (if unique) of the HW group to add this server to")
+	var hwGroup    = flag.String("g", "",     "UUID, name (if unique) or path of the HW group to add this server to")
 	var location   = flag.String("l", "",     "Data centre alias")
 	var acctAlias  = flag.String("a", "",     "Account alias to use")
 	var template   = flag.String("t", "",     "The name of the template to create the server from")
@@ -25,13 +25,19 @@ func main() {
 	var net        = flag.String("net",  "",        "Name of the Network to use")
 	var primDNS    = flag.String("dns1", "8.8.8.8", "Primary DNS to use")
 	var secDNS     = flag.String("dns2", "8.8.4.4", "Secondary DNS to use")
-	var password   = flag.String("pass", "",        "Desired password. Leave blank to auto-generate")
+	var password   = flag.String("pass", "",        "Desired password. Leave blank to generate one that meets the password policy")
 
 	var extraDrv   = flag.Int("drive",  0, "Extra drive (in GB) to add to server. Set to 0 to leave out")
 	var numCpu     = flag.Int("cpu",    1, "Number of Cpus to use")
 	var memGB      = flag.Int("memory", 4, "Amount of memory in GB")
-	var serverType = flag.Int("type",   1, "The type of server to create (1: Standard, 2: Enterprise)")
-	var servLevel  = flag.Int("level",  2, "Data storage service level (1: Premium, 2: Standard)")
+	var serverType = flag.String("type",  "standard", "The type of server to create (standard or enterprise)")
+	var servLevel  = flag.String("level", "standard", "Data storage service level (premium or standard)")
+	var noValidate = flag.Bool("novalidate", false, "Skip the pre-flight validation of the request")
+	var wait       = flag.Bool("wait",       false, "Wait for the server to be deployed, and print its details")
+	var showCreds  = flag.Bool("creds",      false, "Also print the credentials of the new server (implies -wait)")
+	var fields     = utils.KeyValueFlag{}
+
+	flag.Var(fields, "f", "Custom field <name>=<value> to set (may be repeated)")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "usage: %s [options]\n", path.Base(os.Args[0]))
@@ -44,6 +50,15 @@ func main() {
 		os.Exit(0)
 	}
 
+	srvType, err := clcv1.ParseServerType(*serverType)
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+	srvLevel, err := clcv1.ParseServiceLevel(*servLevel)
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+
 	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
 	if err != nil {
 		exit.Fatal(err.Error())
@@ -75,10 +90,10 @@ func main() {
 		HardwareGroupUUID: *hwGroup,
 
 		//The type of server to create (required)
-		ServerType: *serverType,
+		ServerType: srvType,
 
 		// The service level/performance for the underlying data store (required)
-		ServiceLevel: *servLevel,
+		ServiceLevel: srvLevel,
 
 		// The number of processors to configure the server with (required)
 		Cpu: *numCpu,
@@ -108,22 +123,53 @@ func main() {
 		// Leave blank to have the system generate a password
 		Password: *password,
 
-		// A list of Custom Fields associated to this server
-		// FIXME: not supported yet
+		// A list of Custom Fields associated to this server (set below)
 		CustomFields: nil,
 	}
 
-	/* hwGroup may be hex uuid or group name */
-	if _, err := hex.DecodeString(*hwGroup); err != nil {
-		if group, err := client.GetGroupByName(*hwGroup, *location, *acctAlias); err != nil {
-			exit.Errorf("Failed to resolve group name %q: %s", *hwGroup, err)
-		} else if group == nil {
-			exit.Errorf("No group named %q was found on %s", *hwGroup, *location)
-		} else {
-			req.HardwareGroupUUID = group.UUID
+	if len(fields) > 0 {
+		resolver, err := client.LoadCustomFieldResolver(*acctAlias)
+		if err != nil {
+			exit.Fatal(err.Error())
+		} else if req.CustomFields, err = resolver.Values(fields); err != nil {
+			exit.Fatalf("Invalid custom fields: %s", err)
 		}
 	}
 
+	/* hwGroup may be a UUID, group name or group path */
+	if req.HardwareGroupUUID, err = client.ResolveGroup(*hwGroup, *location, *acctAlias); err != nil {
+		exit.Fatal(err.Error())
+	}
+
+	if req.Password == "" {
+		if req.Password, err = clcv1.DefaultPasswordPolicy.Generate(0); err != nil {
+			exit.Fatalf("Failed to generate password: %s", err)
+		}
+		fmt.Println("Using generated password (use get_credentials to retrieve it after creation).")
+	}
+
+	if !*noValidate {
+		if err := client.ValidateCreateServer(&req); err != nil {
+			if problems, ok := err.(clcv1.ValidationError); ok {
+				fmt.Fprintf(os.Stderr, "Invalid server creation request:\n")
+				for _, p := range problems {
+					fmt.Fprintf(os.Stderr, "  - %s\n", p)
+				}
+				os.Exit(1)
+			}
+			exit.Fatalf("Failed to validate server creation request: %s", err)
+		}
+	}
+
+	if *wait || *showCreds {
+		server, err := client.CreateServerAndWait(&req, *showCreds)
+		if err != nil {
+			exit.Fatalf("Failed to create server: %s", err)
+		}
+		printProvisionedServer(server)
+		return
+	}
+
 	reqId, err := client.CreateServer(&req)
 	if err != nil {
 		exit.Fatalf("Failed to create server: %s", err)
@@ -131,3 +177,12 @@ func main() {
 
 	fmt.Println("Request ID for server creation:", reqId)
 }
+
+// Print the essential details of a newly deployed server.
+func printProvisionedServer(s *clcv1.ProvisionedServer) {
+	fmt.Printf("Created %s (%s) in %s: %d CPU, %d GB memory, %d GB storage, IP %s, %s\n",
+		   s.Name, s.OperatingSystem, s.Location, s.Cpu, s.MemoryGB, s.TotalDiskSpaceGB, s.IPAddress, s.PowerState)
+	if s.Credentials != nil {
+		fmt.Printf("Credentials: %s / %s\n", s.Credentials.Username, s.Credentials.Password)
+	}
+}
@@ -17,7 +17,8 @@ func main() {
 	var acctAlias = flag.String("a",   "", "Account alias to use")
 	var busId     = flag.String("bus", "", "The SCSI bus ID of the disk")
 	var devId     = flag.String("dev", "", "The SCSI device ID of the disk")
-	var force     = flag.Bool("force", false, "Override safety checks required for primary/OS disks")
+	var allowOS   = flag.Bool("allow-os",           false, "Lift the client-side check that refuses to delete primary/OS disks")
+	var failsafe  = flag.Bool("override-failsafes", false, "Override the server-side failsafes of DeleteDisk (needed in addition to -allow-os for OS disks)")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "usage: %s [options]  <server-name>\n", path.Base(os.Args[0]))
@@ -37,7 +38,22 @@ func main() {
 		exit.Fatalf("Login failed: %s", err)
 	}
 
-	reqId, err := client.DeleteDisk(flag.Arg(0), *acctAlias, *busId, *devId, *force)
+	addr, err := clcv1.ParseScsiAddress(*busId, *devId)
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+
+	disks, err := client.GetDisks(flag.Arg(0), *acctAlias)
+	if err != nil {
+		exit.Fatalf("Failed to list disks: %s", err)
+	}
+
+	disk := clcv1.FindDiskByAddress(disks, addr)
+	if disk == nil {
+		exit.Fatalf("No disk with SCSI address %s on %s", addr, flag.Arg(0))
+	}
+
+	reqId, err := client.RemoveDisk(disk, *acctAlias, *allowOS, *failsafe)
 	if err != nil {
 		exit.Fatalf("Failed to delete disk on %s: %s", flag.Arg(0), err)
 	}
@@ -0,0 +1,65 @@
+/*
+ * Grow a server disk, identified by mount point / drive letter or SCSI address, to a target size.
+ */
+package main
+
+import (
+	"github.com/grrtrr/clcv1"
+	"github.com/grrtrr/exit"
+	"path"
+	"flag"
+	"log"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var acctAlias = flag.String("a",     "",    "Account alias to use")
+	var mount     = flag.String("m",     "",    "Mount point or drive letter of the disk (e.g. /data or E:)")
+	var scsi      = flag.String("scsi",  "",    "SCSI address <bus>:<device> of the disk (alternative to -m)")
+	var targetGB  = flag.Int("s",        0,     "Target size of the disk in GB")
+	var expand    = flag.Bool("e",       false, "Expand the filesystem on the disk after the resize")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [options]  <server-name>\n", path.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+	if flag.NArg() != 1 || *targetGB <= 0 || (*mount == "") == (*scsi == "") {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if err := client.Logon("", ""); err != nil {
+		exit.Fatalf("Login failed: %s", err)
+	}
+
+	disks, err := client.GetDisks(flag.Arg(0), *acctAlias)
+	if err != nil {
+		exit.Fatalf("Failed to list disks: %s", err)
+	}
+
+	var disk *clcv1.Disk
+	if *mount != "" {
+		if disk = clcv1.FindDiskByMount(disks, *mount); disk == nil {
+			exit.Fatalf("No disk mounted at %q on %s", *mount, flag.Arg(0))
+		}
+	} else if addr, err := clcv1.ParseScsiAddressString(*scsi); err != nil {
+		exit.Fatal(err.Error())
+	} else if disk = clcv1.FindDiskByAddress(disks, addr); disk == nil {
+		exit.Fatalf("No disk with SCSI address %s on %s", addr, flag.Arg(0))
+	}
+
+	reqId, err := client.GrowDisk(disk, *acctAlias, *targetGB, *expand)
+	if err != nil {
+		exit.Fatalf("Failed to grow disk %s: %s", disk, err)
+	} else if reqId == 0 {
+		fmt.Printf("Disk %s is already at least %d GB - nothing to do.\n", disk, *targetGB)
+	} else {
+		fmt.Printf("Request ID for growing disk %s to %d GB: %d\n", disk, *targetGB, reqId)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ * Fleet-wide disk inventory (for a given HW group, for a given location).
+ */
+package main
+
+import (
+	"github.com/olekukonko/tablewriter"
+	"github.com/grrtrr/clcv1"
+	"github.com/grrtrr/exit"
+	"path"
+	"flag"
+	"log"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var acctAlias = flag.String("a", "", "Account alias of the account that owns the servers")
+	var hwGrpUUID = flag.String("u", "", "UUID or path (e.g. WA1/Default Group/Web) of the Hardware Group")
+	var location  = flag.String("l", "", "The data center location")
+	var parallel  = flag.Int("j",    4,  "Maximum number of concurrent requests")
+	var noOS      = flag.Bool("x",   false, "Exclude operating system disks")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [options]\n", path.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if err := client.Logon("", ""); err != nil {
+		exit.Fatalf("Login failed: %s", err)
+	}
+
+	if *hwGrpUUID != "" {
+		if *hwGrpUUID, err = client.ResolveGroup(*hwGrpUUID, *location, *acctAlias); err != nil {
+			exit.Fatal(err.Error())
+		}
+	}
+
+	disks, err := client.DiskInventory(*acctAlias, *hwGrpUUID, *location, *parallel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", err)
+	}
+
+	if len(disks) == 0 {
+		println("Empty result.")
+		os.Exit(0)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoFormatHeaders(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(true)
+
+	table.SetHeader([]string{ "Server", "OS", "SCSI", "Mount", "Size/GB", "OS Disk?" })
+
+	totalGB := 0
+	for _, d := range disks {
+		if *noOS && d.IsOSDisk {
+			continue
+		}
+		totalGB += d.SizeGB
+		table.Append([]string{
+			d.Server, fmt.Sprint(d.OperatingSystem), d.ScsiAddress.String(),
+			d.MountName, fmt.Sprint(d.SizeGB), fmt.Sprint(d.IsOSDisk),
+		})
+	}
+	table.Render()
+	fmt.Printf("Total: %d GB\n", totalGB)
+}
@@ -0,0 +1,86 @@
+/*
+ * Bring servers, or all servers of a hardware group, into a desired power state.
+ */
+package main
+
+import (
+	"github.com/olekukonko/tablewriter"
+	"github.com/grrtrr/clcv1"
+	"github.com/grrtrr/exit"
+	"strings"
+	"path"
+	"flag"
+	"log"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var acctAlias = flag.String("a",     "", "Account alias to use")
+	var hwGrpUUID = flag.String("g",     "", "UUID, name or path of the Hardware Group (instead of server names)")
+	var location  = flag.String("l",     "", "The data center location of the Hardware Group")
+	var hard      = flag.Bool("hard", false, "Use hard power-off instead of graceful shutdown")
+	var parallel  = flag.Int("p",         4, "Maximum number of servers to handle concurrently")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [options]  <Started|Stopped|Paused> [<server-name> ...]\n", path.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+	if flag.NArg() < 1 || (flag.NArg() == 1) == (*hwGrpUUID == "") {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	want, err := clcv1.ParsePowerState(flag.Arg(0))
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+
+	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if err := client.Logon("", ""); err != nil {
+		exit.Fatalf("Login failed: %s", err)
+	}
+
+	var changes []*clcv1.PowerChange
+	var opts = clcv1.PowerStateOptions{ Hard: *hard, Parallel: *parallel }
+
+	if *hwGrpUUID != "" {
+		if changes, err = client.EnsureGroupPowerState(*hwGrpUUID, *location, *acctAlias, want, opts); err != nil {
+			exit.Fatalf("Failed to change power state of group %s: %s", *hwGrpUUID, err)
+		}
+	} else {
+		for _, name := range flag.Args()[1:] {
+			changes = append(changes, client.EnsurePowerState(name, *acctAlias, want, opts))
+		}
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoFormatHeaders(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(true)
+
+	table.SetHeader([]string{ "Server", "From", "To", "Steps", "Error" })
+
+	var failed int
+	for _, c := range changes {
+		var steps, errMsg = strings.Join(c.Steps, ", "), ""
+
+		if !c.Changed() && c.Err == nil {
+			steps = "(unchanged)"
+		}
+		if c.Err != nil {
+			errMsg = c.Err.Error()
+			failed++
+		}
+		table.Append([]string{ c.Server, string(c.From), string(c.To), steps, errMsg })
+	}
+	table.Render()
+
+	if failed > 0 {
+		exit.Fatalf("Failed to change the power state of %d server(s)", failed)
+	}
+}
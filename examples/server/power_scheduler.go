@@ -0,0 +1,101 @@
+/*
+ * Power servers and groups on/off according to cron-style schedules, e.g. to stop
+ * non-production groups at night and on weekends.
+ *
+ * The schedule file is a JSON array of entries such as
+ *   { "Target": "WA1/Default Group/Dev", "Schedule": "0 19 * * mon-fri", "Action": "shutdown" },
+ *   { "Target": "WA1/Default Group/Dev", "Schedule": "0 7 * * mon-fri",  "Action": "on" },
+ *   { "Target": "WA1ACCTBUILD01",        "Schedule": "30 22 * * *",      "Action": "shutdown" }
+ */
+package main
+
+import (
+	"github.com/grrtrr/clcv1"
+	"github.com/grrtrr/exit"
+	"os/signal"
+	"strings"
+	"syscall"
+	"path"
+	"flag"
+	"time"
+	"log"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var acctAlias = flag.String("a",        "",                "Account alias of the account that owns the servers")
+	var stateFile = flag.String("state",    "clc_power.json",  "File to keep the scheduler state in (empty to disable)")
+	var catchUp   = flag.Duration("catchup", 24 * time.Hour,   "Maximum age of missed windows to catch up on")
+	var once      = flag.Bool("once",     false,               "Handle due schedules once and exit (e.g. when run from cron)")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [options]  <schedule.json>\n", path.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	schedules, err := clcv1.LoadPowerSchedules(flag.Arg(0))
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+
+	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if err := client.Logon("", ""); err != nil {
+		exit.Fatalf("Login failed: %s", err)
+	}
+
+	sched, err := client.NewPowerScheduler(schedules, *acctAlias, *stateFile)
+	if err != nil {
+		exit.Fatalf("Failed to set up power scheduler: %s", err)
+	}
+	sched.MaxCatchUp = *catchUp
+
+	report := func(ev *clcv1.PowerEvent) {
+		var due string
+
+		if time.Since(ev.Due) > time.Minute {
+			due = fmt.Sprintf(" (missed window %s)", ev.Due.Format(time.Stamp))
+		}
+		if ev.Err != nil {
+			log.Printf("%-8s %s%s: FAILED: %s", ev.Schedule.Action, ev.Target, due, ev.Err)
+		} else {
+			log.Printf("%-8s %s%s: OK", ev.Schedule.Action, ev.Target, due)
+		}
+		for name, reason := range ev.Skipped {
+			log.Printf("\tskipped %s: %s", name, reason)
+		}
+	}
+
+	if *once {
+		if err := sched.RunOnce(time.Now(), report); err != nil {
+			exit.Fatal(err.Error())
+		}
+		return
+	}
+
+	for _, s := range schedules {
+		log.Printf("Schedule: %-8s %-40s %s", s.Action, s.Target, strings.TrimSpace(s.Schedule))
+	}
+	if next := sched.NextDue(time.Now()); !next.IsZero() {
+		log.Printf("Next action due at %s", next.Format(time.Stamp))
+	}
+
+	stop, sig := make(chan struct{}), make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sig
+		close(stop)
+	}()
+
+	if err := sched.Run(stop, report); err != nil {
+		exit.Fatal(err.Error())
+	}
+}
@@ -4,6 +4,7 @@
 package main
 
 import (
+	"github.com/grrtrr/clcv1/utils"
 	"github.com/grrtrr/clcv1"
 	"github.com/grrtrr/exit"
 	"path"
@@ -15,7 +16,7 @@ import (
 
 func main() {
 	var acctAlias = flag.String("a", "", "Account alias to use")
-	var hwGrpUUID = flag.String("u", "", "UUID of the Hardware Group to restore the server to")
+	var hwGrpUUID = flag.String("u", "", "UUID, name or path of the Hardware Group to restore the server to")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "usage: %s [options]  <server-name>\n", path.Base(os.Args[0]))
@@ -35,6 +36,14 @@ func main() {
 		exit.Fatalf("Login failed: %s", err)
 	}
 
+	if *hwGrpUUID != "" {
+		/* A group name is looked up in the data center of the server. */
+		location := utils.ExtractLocationFromServerName(flag.Arg(0))
+		if *hwGrpUUID, err = client.ResolveGroup(*hwGrpUUID, location, *acctAlias); err != nil {
+			exit.Fatal(err.Error())
+		}
+	}
+
 	reqId, err := client.RestoreServer(flag.Arg(0), *acctAlias, *hwGrpUUID)
 	if err != nil {
 		exit.Fatalf("Failed to restore server %s: %s", flag.Arg(0), err)
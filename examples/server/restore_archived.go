@@ -0,0 +1,103 @@
+/*
+ * Search archived servers by name pattern and restore them into a group path.
+ */
+package main
+
+import (
+	"github.com/olekukonko/tablewriter"
+	"github.com/grrtrr/clcv1"
+	"github.com/grrtrr/exit"
+	"strings"
+	"path"
+	"flag"
+	"log"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var acctAlias = flag.String("a",        "", "Account alias to use")
+	var locations = flag.String("l",        "", "Comma-separated locations to search (default: all)")
+	var groupPath = flag.String("g",        "", "Destination group path, e.g. \"WA1/Default Group/Restored\"")
+	var powerOn   = flag.Bool("on",      false, "Power on the servers after restoring them")
+	var parallel  = flag.Int("p",            4, "Maximum number of servers to restore concurrently")
+	var dryRun    = flag.Bool("dry",     false, "Only list the matching archived servers")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [options]  <name-pattern> [<name-pattern> ...]\n", path.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+	if flag.NArg() == 0 || (*groupPath == "" && !*dryRun) {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if err := client.Logon("", ""); err != nil {
+		exit.Fatalf("Login failed: %s", err)
+	}
+
+	var locs []string
+	if *locations != "" {
+		for _, l := range strings.Split(*locations, ",") {
+			locs = append(locs, strings.ToUpper(strings.TrimSpace(l)))
+		}
+	}
+
+	var servers []clcv1.ArchivedServerAt
+	var seen    = make(map[string]bool)
+	for _, pattern := range flag.Args() {
+		matches, err := client.FindArchivedServers(pattern, *acctAlias, locs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: %s\n", err)
+		}
+		for _, m := range matches {
+			if !seen[m.Name] {
+				fmt.Printf("%-30s %-6s %s\n", m.Name, m.Location, m.Description)
+				servers, seen[m.Name] = append(servers, m), true
+			}
+		}
+	}
+
+	if len(servers) == 0 {
+		exit.Fatalf("No archived servers match %s", strings.Join(flag.Args(), ", "))
+	} else if *dryRun {
+		return
+	}
+
+	fmt.Printf("Restoring %d server(s) into %s ...\n", len(servers), *groupPath)
+	results, err := client.RestoreServers(servers, *groupPath, *acctAlias, clcv1.RestoreOptions{
+		PowerOn:  *powerOn,
+		Parallel: *parallel,
+	})
+	if err != nil {
+		exit.Fatalf("Failed to restore servers: %s", err)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoFormatHeaders(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(true)
+
+	table.SetHeader([]string{ "Server", "Request ID", "Powered on", "Error" })
+
+	var failed int
+	for _, r := range results {
+		var errMsg string
+
+		if r.Err != nil {
+			errMsg = r.Err.Error()
+			failed++
+		}
+		table.Append([]string{ r.Server, fmt.Sprint(r.RequestID), fmt.Sprint(r.PoweredOn), errMsg })
+	}
+	table.Render()
+
+	if failed > 0 {
+		exit.Fatalf("Failed to restore %d of %d server(s)", failed, len(results))
+	}
+}
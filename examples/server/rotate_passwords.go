@@ -0,0 +1,161 @@
+/*
+ * Rotate the administrator/root passwords of several servers.
+ * The new credentials are written to a new passphrase-encrypted file; they are only printed
+ * (to stderr) if writing that file fails after the passwords have been changed.
+ */
+package main
+
+import (
+	"github.com/olekukonko/tablewriter"
+	"github.com/grrtrr/clcv1/utils"
+	"github.com/grrtrr/clcv1"
+	"github.com/grrtrr/exit"
+	"encoding/json"
+	"time"
+	"path"
+	"flag"
+	"log"
+	"fmt"
+	"os"
+)
+
+/* Record written to the encrypted output file */
+type rotatedCredentials struct {
+	Server		string
+	Username	string
+	Password	string
+	Verified	bool
+	Changed		time.Time
+	Error		string	`json:",omitempty"`
+}
+
+func main() {
+	var acctAlias = flag.String("a",       "", "Account alias to use")
+	var hwGrpUUID = flag.String("u",       "", "Rotate the passwords of all servers of this Hardware Group (UUID or path)")
+	var location  = flag.String("l",       "", "The data center location (with -u)")
+	var outFile   = flag.String("o",       "", "Encrypted file to write the new credentials to")
+	var decrypt   = flag.String("decrypt", "", "Decrypt and print the contents of a previously written output file")
+	var parallel  = flag.Int("j",          4,  "Maximum number of servers to process concurrently")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [options] -o <output-file>  [<server-name> ...]\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "       %s -decrypt <output-file>\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "The passphrase is read from CLC_ROTATION_PASSPHRASE, or prompted for.\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *decrypt != "" {
+		data, err := utils.DecryptFile(*decrypt, passphrase())
+		if err != nil {
+			exit.Fatal(err.Error())
+		}
+		fmt.Printf("%s\n", data)
+		os.Exit(0)
+	} else if *outFile == "" || (flag.NArg() == 0 && *hwGrpUUID == "") {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	/* Obtain the passphrase before changing anything, so that the results can always be saved. */
+	pass := passphrase()
+
+	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if err := client.Logon("", ""); err != nil {
+		exit.Fatalf("Login failed: %s", err)
+	}
+
+	if *hwGrpUUID != "" {
+		if *hwGrpUUID, err = client.ResolveGroup(*hwGrpUUID, *location, *acctAlias); err != nil {
+			exit.Fatal(err.Error())
+		}
+	}
+
+	names := flag.Args()
+	if *hwGrpUUID != "" {
+		servers, err := client.GetAllServers(*acctAlias, *hwGrpUUID, *location)
+		if err != nil {
+			exit.Fatalf("Failed to list servers of %s: %s", *hwGrpUUID, err)
+		}
+		for _, s := range servers {
+			if !s.IsTemplate {
+				names = append(names, s.Name)
+			}
+		}
+	}
+
+	/* Create the output file before changing anything; refuse to overwrite existing credentials. */
+	out, err := os.OpenFile(*outFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		exit.Fatalf("Can not create output file: %s", err)
+	}
+
+	results := client.RotatePasswords(names, *acctAlias, *parallel)
+
+	var records []rotatedCredentials
+	for _, r := range results {
+		rec := rotatedCredentials{
+			Server:   r.Server,
+			Username: r.Username,
+			Password: r.Password,
+			Verified: r.Verified,
+			Changed:  r.Changed,
+		}
+		if r.Err != nil {
+			rec.Error = r.Err.Error()
+		}
+		records = append(records, rec)
+	}
+
+	data, err := json.MarshalIndent(records, "", "\t")
+	if err != nil {
+		exit.Fatalf("Failed to encode results: %s", err)
+	}
+	if err = utils.EncryptTo(out, pass, data); err == nil {
+		err = out.Close()
+	}
+	if err != nil {
+		/* The passwords have already been changed - do not lose them. */
+		fmt.Fprintf(os.Stderr, "%s\n", data)
+		exit.Fatalf("Failed to write %s (new credentials printed above): %s", *outFile, err)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoFormatHeaders(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(true)
+
+	table.SetHeader([]string{ "Server", "User", "Changed", "Verified", "Error" })
+
+	failed := 0
+	for _, r := range records {
+		changed := "no"
+		if r.Password != "" {
+			changed = "yes"
+		}
+		if r.Error != "" {
+			failed++
+		}
+		table.Append([]string{ r.Server, r.Username, changed, fmt.Sprint(r.Verified), r.Error })
+	}
+	table.Render()
+	fmt.Printf("New credentials saved in %s\n", *outFile)
+
+	if failed > 0 {
+		exit.Errorf("Password rotation failed for %d of %d servers", failed, len(records))
+	}
+}
+
+// Return the passphrase for the output file.
+func passphrase() string {
+	if pass := os.Getenv("CLC_ROTATION_PASSPHRASE"); pass != "" {
+		return pass
+	}
+	pass, err := utils.GetPass("Output file passphrase")
+	if err != nil {
+		exit.Fatalf("Failed to read passphrase: %s", err)
+	}
+	return pass
+}
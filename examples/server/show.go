@@ -86,7 +86,7 @@ func main() {
 			server.Name, grp.Name, server.Description, fmt.Sprint(server.OperatingSystem),
 			fmt.Sprint(server.Cpu),	fmt.Sprintf("%dGB", server.TotalDiskSpaceGB),
 			strings.Join(IPs, " "),
-			server.PowerState, modifiedStr,
+			server.PowerState.String(), modifiedStr,
 		})
 		table.Render()
 	}
@@ -0,0 +1,98 @@
+/*
+ * Enforce snapshot lifecycle policies read from a JSON file, e.g.
+ * [
+ *   { "Name": "nightly-web", "Location": "WA1", "HardwareGroupUUID": "...", "SnapshotEveryHours": 23 },
+ *   { "Name": "cleanup",     "Servers": [ "WA1ABCDWEB01" ],                  "MaxAgeDays": 7 }
+ * ]
+ */
+package main
+
+import (
+	"github.com/olekukonko/tablewriter"
+	"github.com/grrtrr/clcv1"
+	"github.com/grrtrr/exit"
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"flag"
+	"log"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var acctAlias = flag.String("a",     "",    "Account alias to use")
+	var apply     = flag.Bool("apply",   false, "Carry out the actions (default is to only report them)")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [options]  <policy-file.json>\n", path.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	var policies []clcv1.SnapshotPolicy
+	if data, err := ioutil.ReadFile(flag.Arg(0)); err != nil {
+		exit.Fatalf("Failed to read policy file: %s", err)
+	} else if err = json.Unmarshal(data, &policies); err != nil {
+		exit.Fatalf("Failed to decode policies in %s: %s", flag.Arg(0), err)
+	}
+
+	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if err := client.Logon("", ""); err != nil {
+		exit.Fatalf("Login failed: %s", err)
+	}
+
+	actions, err := client.PlanSnapshotPolicies(policies, *acctAlias)
+	if err != nil {
+		exit.Fatalf("Failed to evaluate snapshot policies: %s", err)
+	}
+
+	if len(actions) == 0 {
+		println("Nothing to do.")
+		os.Exit(0)
+	}
+
+	failed := 0
+	if *apply {
+		failed = client.ApplySnapshotActions(actions, *acctAlias)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoFormatHeaders(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(true)
+
+	table.SetHeader([]string{ "Policy", "Server", "Action", "Snapshot", "Date Created", "Age (days)", "Reason", "Result" })
+	for _, a := range actions {
+		var created, age, result string
+
+		if !a.DateCreated.IsZero() {
+			created = a.DateCreated.Format("Jan _2/06 15:04")
+			age     = fmt.Sprintf("%.1f", a.Age().Hours() / 24)
+		}
+
+		if !*apply {
+			result = "dry run"
+		} else if a.Err != nil {
+			result = a.Err.Error()
+		} else if a.RequestID != 0 {
+			result = fmt.Sprintf("Request ID %d", a.RequestID)
+		} else {
+			result = "OK"
+		}
+		table.Append([]string{ a.Policy, a.Server, a.Type.String(), a.Snapshot, created, age, a.Reason, result })
+	}
+	table.Render()
+
+	if failed > 0 {
+		exit.Errorf("%d of %d actions failed", failed, len(actions))
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ * Set custom fields ("tags") on several servers at once, leaving the rest of their configuration as is.
+ */
+package main
+
+import (
+	"github.com/olekukonko/tablewriter"
+	"github.com/grrtrr/clcv1/utils"
+	"github.com/grrtrr/clcv1"
+	"github.com/grrtrr/exit"
+	"strings"
+	"path"
+	"flag"
+	"log"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var acctAlias = flag.String("a", "", "Account alias to use")
+	var hwGrpUUID = flag.String("u", "", "Tag all servers of this Hardware Group (UUID or path), including its sub-groups")
+	var location  = flag.String("l", "", "The data center location (with -u)")
+	var parallel  = flag.Int("j",    4,  "Maximum number of servers to reconfigure concurrently")
+	var tags      = utils.KeyValueFlag{}
+
+	flag.Var(tags, "f", "Custom field <name>=<value> to set (may be repeated)")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [options] -f <name>=<value> [-f ...]  [<server-name> ...]\n", path.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+	if len(tags) == 0 || (flag.NArg() == 0 && *hwGrpUUID == "") {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if err := client.Logon("", ""); err != nil {
+		exit.Fatalf("Login failed: %s", err)
+	}
+
+	if *hwGrpUUID != "" {
+		if *hwGrpUUID, err = client.ResolveGroup(*hwGrpUUID, *location, *acctAlias); err != nil {
+			exit.Fatal(err.Error())
+		}
+	}
+
+	names := flag.Args()
+	if *hwGrpUUID != "" {
+		servers, err := client.GetAllServers(*acctAlias, *hwGrpUUID, *location)
+		if err != nil {
+			exit.Fatalf("Failed to list servers of %s: %s", *hwGrpUUID, err)
+		}
+		for _, s := range servers {
+			if !s.IsTemplate {
+				names = append(names, s.Name)
+			}
+		}
+	}
+
+	resolver, err := client.LoadCustomFieldResolver(*acctAlias)
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+
+	results, err := client.TagServers(resolver, names, tags, *acctAlias, *parallel)
+	if err != nil {
+		exit.Fatalf("Invalid custom fields: %s", err)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoFormatHeaders(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(true)
+
+	table.SetHeader([]string{ "Server", "Changes", "Result" })
+
+	failed := 0
+	for _, r := range results {
+		var changes []string
+		var result = "unchanged"
+
+		for _, c := range r.Changes {
+			changes = append(changes, c.String())
+		}
+		if r.Err != nil {
+			result = r.Err.Error()
+			failed++
+		} else if r.RequestID != 0 {
+			result = fmt.Sprintf("Request ID %d", r.RequestID)
+		}
+		table.Append([]string{ r.Server, strings.Join(changes, ", "), result })
+	}
+	table.Render()
+
+	if failed > 0 {
+		exit.Errorf("Failed to tag %d of %d servers", failed, len(results))
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * List servers by their custom field values ("tags"), or report servers missing required fields.
+ */
+package main
+
+import (
+	"github.com/olekukonko/tablewriter"
+	"github.com/grrtrr/clcv1/utils"
+	"github.com/grrtrr/clcv1"
+	"github.com/grrtrr/exit"
+	"strings"
+	"path"
+	"flag"
+	"sort"
+	"log"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var acctAlias = flag.String("a",     "",    "Account alias of the account that owns the servers")
+	var hwGrpUUID = flag.String("u",     "",    "UUID or path (e.g. WA1/Default Group/Web) of the Hardware Group")
+	var location  = flag.String("l",     "",    "The data center location")
+	var missing   = flag.Bool("missing", false, "Report servers that are missing required custom fields")
+	var tags      = utils.KeyValueFlag{}
+
+	flag.Var(tags, "f", "Only list servers whose custom field <name> has <value> (may be repeated)")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [options]\n", path.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if err := client.Logon("", ""); err != nil {
+		exit.Fatalf("Login failed: %s", err)
+	}
+
+	if *hwGrpUUID != "" {
+		if *hwGrpUUID, err = client.ResolveGroup(*hwGrpUUID, *location, *acctAlias); err != nil {
+			exit.Fatal(err.Error())
+		}
+	}
+
+	resolver, err := client.LoadCustomFieldResolver(*acctAlias)
+	if err != nil {
+		exit.Fatal(err.Error())
+	}
+
+	servers, err := client.GetAllServers(*acctAlias, *hwGrpUUID, *location)
+	if err != nil {
+		exit.Fatalf("Failed to list all servers: %s", err)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoFormatHeaders(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(true)
+
+	if *missing {
+		report := resolver.MissingRequired(servers)
+		if len(report) == 0 {
+			println("All servers have the required custom fields set.")
+			os.Exit(0)
+		}
+
+		var names []string
+		for name := range report {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		table.SetHeader([]string{ "Server", "Missing required fields" })
+		for _, name := range names {
+			table.Append([]string{ name, strings.Join(report[name], ", ") })
+		}
+		table.Render()
+		os.Exit(1)
+	}
+
+	matching, err := resolver.FilterServers(servers, tags)
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if len(matching) == 0 {
+		println("Empty result.")
+		os.Exit(0)
+	}
+
+	var columns []string
+	for _, d := range resolver.Definitions() {
+		columns = append(columns, d.Name)
+	}
+
+	table.SetHeader(append([]string{ "Server" }, columns...))
+	for _, s := range matching {
+		fields := resolver.ToMap(s.CustomFields)
+		row    := []string{ s.Name }
+		for _, c := range columns {
+			row = append(row, fields[c])
+		}
+		table.Append(row)
+	}
+	table.Render()
+}
@@ -0,0 +1,106 @@
+/*
+ * Search the server templates of all data centers.
+ */
+package main
+
+import (
+	"github.com/olekukonko/tablewriter"
+	"github.com/grrtrr/clcv1"
+	"github.com/grrtrr/exit"
+	"strings"
+	"path"
+	"flag"
+	"log"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var acctAlias = flag.String("a",        "", "Account alias to use")
+	var osName    = flag.String("os",       "", "Operating system family or distribution (e.g. Linux, Ubuntu, Windows)")
+	var version   = flag.String("version",  "", "Operating system version (e.g. 14, \"2012 R2\")")
+	var bits      = flag.Int("bits",         0, "Architecture word size (32 or 64)")
+	var edition   = flag.String("edition",  "", "Windows edition (e.g. Datacenter)")
+	var locations = flag.String("l",        "", "Comma-separated locations that the template must be available at")
+	var latest    = flag.Bool("latest",  false, "Only show the template(s) with the latest OS version")
+	var missing   = flag.Bool("missing", false, "Report templates that are missing from some locations")
+	var parallel  = flag.Int("p",            4, "Maximum number of locations to query concurrently")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [options]\n", path.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	client, err := clcv1.NewClient(log.New(os.Stdout, "", log.LstdFlags | log.Ltime))
+	if err != nil {
+		exit.Fatal(err.Error())
+	} else if err := client.Logon("", ""); err != nil {
+		exit.Fatalf("Login failed: %s", err)
+	}
+
+	catalog, err := client.LoadTemplateCatalog(*acctAlias, *parallel)
+	if catalog == nil {
+		exit.Fatalf("Failed to load template catalog: %s", err)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", err)
+	}
+
+	query := &clcv1.TemplateQuery{
+		OS:      *osName,
+		Version: *version,
+		Bits:    *bits,
+		Edition: *edition,
+		Latest:  *latest,
+	}
+	if *locations != "" {
+		for _, l := range strings.Split(*locations, ",") {
+			query.Locations = append(query.Locations, strings.ToUpper(strings.TrimSpace(l)))
+		}
+	}
+
+	entries := catalog.Find(query)
+	if *missing {
+		var incomplete []*clcv1.CatalogEntry
+
+		for _, e := range entries {
+			if len(e.MissingFrom) > 0 {
+				incomplete = append(incomplete, e)
+			}
+		}
+		entries = incomplete
+	}
+
+	if len(entries) == 0 {
+		println("Empty result.")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoFormatHeaders(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(true)
+
+	if *missing {
+		table.SetHeader([]string{ "Name", "OS", "Missing from", "Available at" })
+	} else {
+		table.SetHeader([]string{ "Name", "OS", "Available at" })
+	}
+
+	for _, e := range entries {
+		if *missing {
+			table.Append([]string{
+				e.Name, e.OperatingSystem.String(),
+				strings.Join(e.MissingFrom, ", "), strings.Join(e.Locations, ", "),
+			})
+		} else {
+			table.Append([]string{ e.Name, e.OperatingSystem.String(), strings.Join(e.Locations, ", ") })
+		}
+	}
+	table.Render()
+}
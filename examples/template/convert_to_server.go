@@ -15,9 +15,12 @@ import (
 
 func main() {
 	var acctAlias = flag.String("a",    "", "Account alias to use")
-	var hwGrpUUID = flag.String("u",    "", "UUID of the Hardware Group to place the converted server in")
+	var hwGrpUUID = flag.String("u",    "", "UUID or path (e.g. WA1/Default Group/Web) of the Hardware Group to place the converted server in")
 	var password  = flag.String("pass", "", "New administrator/root password for the converted server")
 	var network   = flag.String("net",  "", "Name of the network to use for the converted server")
+	var location  = flag.String("l",    "", "Data centre alias of the template")
+	var wait      = flag.Bool("wait",  false, "Wait for the server to be deployed, and print its details")
+	var showCreds = flag.Bool("creds", false, "Also print the credentials of the new server (implies -wait)")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "usage: %s [options]  <server-name>\n", path.Base(os.Args[0]))
@@ -37,6 +40,24 @@ func main() {
 		exit.Fatalf("Login failed: %s", err)
 	}
 
+	if *hwGrpUUID != "" {
+		if *hwGrpUUID, err = client.ResolveGroup(*hwGrpUUID, *location, *acctAlias); err != nil {
+			exit.Fatal(err.Error())
+		}
+	}
+
+	if *wait || *showCreds {
+		s, err := client.ConvertTemplateToServerAndWait(flag.Arg(0), *password, *hwGrpUUID, *network, *acctAlias, *location, *showCreds)
+		if err != nil {
+			exit.Fatalf("Failed to generate a server from %s: %s", flag.Arg(0), err)
+		}
+		fmt.Printf("Created %s (%s) in %s, IP %s, %s\n", s.Name, s.OperatingSystem, s.Location, s.IPAddress, s.PowerState)
+		if s.Credentials != nil {
+			fmt.Printf("Credentials: %s / %s\n", s.Credentials.Username, s.Credentials.Password)
+		}
+		return
+	}
+
 	reqId, err := client.ConvertTemplateToServer(flag.Arg(0), *password, *hwGrpUUID, *network, *acctAlias)
 	if err != nil {
 		exit.Fatalf("Failed to generate a server from %s: %s", flag.Arg(0), err)
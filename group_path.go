@@ -0,0 +1,262 @@
+/*
+ * Human-readable hardware group paths of the form "<location>/<group>/<subgroup>/...",
+ * e.g. "WA1/Default Group/Prod/Web".
+ */
+package clcv1
+
+import (
+	"encoding/hex"
+	"strings"
+	"fmt"
+)
+
+// Split group path @path into location and group names; empty elements are dropped.
+func splitGroupPath(path string) (location string, names []string, err error) {
+	for _, elem := range strings.Split(path, "/") {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			names = append(names, elem)
+		}
+	}
+	if len(names) == 0 {
+		return "", nil, fmt.Errorf("Invalid group path %q (expected <location>/<group>/...)", path)
+	}
+	return strings.ToUpper(names[0]), names[1:], nil
+}
+
+// Resolve group path @path (names are matched case-insensitively) within the hierarchy @root.
+// The name of the root group itself may be omitted from @names.
+func findGroupPath(root *GroupNode, names []string) (*GroupNode, error) {
+	var node = root
+
+	if len(names) > 0 && strings.EqualFold(names[0], root.Name) {
+		names = names[1:]
+	}
+
+	for _, name := range names {
+		next, err := findChildGroup(node, name)
+		if err != nil {
+			return nil, err
+		} else if next == nil {
+			return nil, fmt.Errorf("No group %q found below %q", name, node.Name)
+		}
+		node = next
+	}
+	return node, nil
+}
+
+// Return the direct child of @node named @name (case-insensitive), or nil if there is none.
+func findChildGroup(node *GroupNode, name string) (child *GroupNode, err error) {
+	for _, g := range node.Children {
+		if !strings.EqualFold(g.Name, name) {
+			continue
+		} else if child != nil {
+			return nil, fmt.Errorf("Group name %q is ambiguous below %q", name, node.Name)
+		}
+		child = g
+	}
+	return child, nil
+}
+
+// Look up the hardware group identified by @path, e.g. "WA1/Default Group/Prod".
+// The first element of @path is the data center location.
+// @acctAlias: The alias of the account that owns the groups (optional).
+func (c *Client) ResolveGroupPath(path, acctAlias string) (*GroupNode, error) {
+	if !strings.Contains(path, "/") {
+		/* Location only: the root group */
+		path += "/"
+	}
+	return c.LookupGroupNode(path, "", acctAlias, false)
+}
+
+// Return true if @s has the form of a hardware group UUID (32 hex digits).
+func isGroupUUID(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil && len(s) == 32
+}
+
+// Look up the hardware group referenced by @ref (see ResolveGroup) in the hierarchy of its location.
+// @location:  The data center location of the group (ignored if @ref is a path, which names the location).
+// @acctAlias: The alias of the account that owns the group (optional).
+// @servers:   If true, also populate the @Servers field of each group (see GetGroupHierarchy).
+func (c *Client) LookupGroupNode(ref, location, acctAlias string, servers bool) (*GroupNode, error) {
+	var names  []string
+	var err    error
+	var isPath = strings.Contains(ref, "/")
+
+	if isPath {
+		if location, names, err = splitGroupPath(ref); err != nil {
+			return nil, err
+		}
+	} else if location == "" {
+		return nil, fmt.Errorf("Resolving group %q requires a location (or use <location>/<group>/...)", ref)
+	}
+
+	root, err := c.GetGroupHierarchy(location, acctAlias, servers)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load group hierarchy at %s: %s", location, err)
+	}
+
+	if isPath || ref == "" {
+		node, err := findGroupPath(root, names)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to resolve %q: %s", ref, err)
+		}
+		return node, nil
+	}
+
+	var matches []*GroupNode
+	FindGroupNode(root, func(g *GroupNode) bool {
+		if g.UUID == ref || strings.EqualFold(g.Name, ref) {
+			matches = append(matches, g)
+		}
+		return false
+	})
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("No group %q found at %s", ref, location)
+	case 1:
+		return matches[0], nil
+	}
+	return nil, fmt.Errorf("Group name %q is ambiguous at %s - use a group path instead", ref, location)
+}
+
+// Return the path of @g, e.g. "WA1/Default Group/Prod/Web".
+// The root group of the location is represented by the location alias alone.
+// Synthetic nodes above the root group (see GetGroupForest) are not part of the path.
+func (g *GroupNode) Path() string {
+	var names []string
+
+	if g.IsSynthetic() {
+		return ""
+	}
+	for n := g; n.Parent != nil && !n.Parent.IsSynthetic(); n = n.Parent {
+		names = append([]string{ n.Name }, names...)
+	}
+	return strings.Join(append([]string{ g.Location }, names...), "/")
+}
+
+// Resolve the hardware group reference @ref to a group UUID. @ref may be
+// - a group path, e.g. "WA1/Default Group/Prod/Web" (see ResolveGroupPath),
+// - a group UUID, which is returned unchanged, or
+// - a group name, which is looked up at @location and must be unique there.
+// @acctAlias: The alias of the account that owns the groups (optional).
+func (c *Client) ResolveGroup(ref, location, acctAlias string) (uuid string, err error) {
+	if strings.Contains(ref, "/") {
+		node, err := c.ResolveGroupPath(ref, acctAlias)
+		if err != nil {
+			return "", err
+		}
+		return node.UUID, nil
+	} else if isGroupUUID(ref) {
+		return ref, nil
+	} else if location == "" {
+		return "", fmt.Errorf("Resolving group name %q requires a location (or use <location>/<group>/...)", ref)
+	}
+
+	groups, err := c.GetGroups(location, acctAlias)
+	if err != nil {
+		return "", fmt.Errorf("Failed to list groups at %s: %s", location, err)
+	}
+	for _, g := range groups {
+		if strings.EqualFold(g.Name, ref) {
+			if uuid != "" {
+				return "", fmt.Errorf("Group name %q is ambiguous at %s - use a group path instead", ref, location)
+			}
+			uuid = g.UUID
+		}
+	}
+	if uuid == "" {
+		return "", fmt.Errorf("No group named %q was found at %s", ref, location)
+	}
+	return uuid, nil
+}
+
+// Return the path of the hardware group @uuid at @location (see GroupNode.Path).
+// @acctAlias: The alias of the account that owns the group (optional).
+func (c *Client) GroupPath(uuid, location, acctAlias string) (string, error) {
+	root, err := c.GetGroupHierarchy(location, acctAlias, false)
+	if err != nil {
+		return "", fmt.Errorf("Failed to load group hierarchy at %s: %s", location, err)
+	}
+	if node := FindGroupNode(root, func(g *GroupNode) bool { return g.UUID == uuid }); node != nil {
+		return node.Path(), nil
+	}
+	return "", fmt.Errorf("No group with UUID %s found at %s", uuid, location)
+}
+
+// Return the full path of server @name, e.g. "WA1/Default Group/Prod/Web/WA1ACCTWEB01".
+// @acctAlias: The alias of the account that owns the server (optional).
+func (c *Client) ServerPath(name, acctAlias string) (string, error) {
+	server, err := c.GetServer(name, acctAlias)
+	if err != nil {
+		return "", fmt.Errorf("Failed to look up server %s: %s", name, err)
+	}
+
+	groupPath, err := c.GroupPath(server.HardwareGroupUUID, server.Location, acctAlias)
+	if err != nil {
+		return "", err
+	}
+	return groupPath + "/" + server.Name, nil
+}
+
+// Make sure that the hardware group @path, e.g. "UC1/Default Group/Teams/Payments/Staging", exists,
+// creating any missing groups along the path from the top down. Calling it again once the path
+// exists does not create anything, so it is safe to use repeatedly from automation.
+// @path:      The group path, starting with the data center location (see ResolveGroupPath).
+// @desc:      The description to use for newly created groups (optional).
+// @acctAlias: The alias of the account that owns the groups (optional).
+// Returns the leaf group of @path, and the groups that had to be created (in order of creation).
+// If creating a group fails, the groups created up to that point are returned along with the error.
+// This is not atomic: concurrent callers may race to create the same group. If creating a group
+// fails because it has meanwhile been created by someone else, the existing group is used instead;
+// a failure further down leaves the groups created so far in place.
+func (c *Client) EnsureGroupPath(path, desc, acctAlias string) (leaf *GroupNode, created []*GroupNode, err error) {
+	location, names, err := splitGroupPath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root, err := c.GetGroupHierarchy(location, acctAlias, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to load group hierarchy at %s: %s", location, err)
+	}
+
+	leaf = root
+	if len(names) > 0 && strings.EqualFold(names[0], root.Name) {
+		names = names[1:]
+	}
+	for _, name := range names {
+		next, err := findChildGroup(leaf, name)
+		if err != nil {
+			return nil, created, fmt.Errorf("Failed to resolve %q: %s", path, err)
+		} else if next == nil {
+			g, err := c.CreateHardwareGroup(acctAlias, leaf.UUID, name, desc)
+			if err == nil {
+				next = &GroupNode{ HardwareGroup: &g, Parent: leaf, Location: location }
+				created = append(created, next)
+			} else if existing := c.lookupChildGroup(leaf, name, acctAlias); existing != nil {
+				next = &GroupNode{ HardwareGroup: existing, Parent: leaf, Location: location }
+			} else {
+				return nil, created, fmt.Errorf("Failed to create group %q below %s: %s", name, leaf.Path(), err)
+			}
+			leaf.Children = append(leaf.Children, next)
+		}
+		leaf = next
+	}
+	return leaf, created, nil
+}
+
+// Look up the sub-group @name of @parent via GetGroups, e.g. after @parent.Children has become stale.
+// Returns nil if there is no such group, or it could not be looked up.
+func (c *Client) lookupChildGroup(parent *GroupNode, name, acctAlias string) *HardwareGroup {
+	groups, err := c.GetGroups(parent.Location, acctAlias)
+	if err != nil {
+		return nil
+	}
+	for i := range groups {
+		if groups[i].ParentUUID == parent.UUID && strings.EqualFold(groups[i].Name, name) {
+			return &groups[i]
+		}
+	}
+	return nil
+}
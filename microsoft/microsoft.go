@@ -19,9 +19,9 @@ type Timestamp struct {
 	time.Time
 }
 
-// Return @t in Microsoft JSON format
-func (t *Timestamp) MarshalJSON() ([]byte, error) {
-	return []byte(fmt.Sprintf("/Date(%d)/", t.UnixNano()/1000000)), nil
+// Return @t in Microsoft JSON format (as quoted string, so that UnmarshalJSON can read it back)
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"\/Date(%d)\/"`, t.Unix()*1000 + int64(t.Nanosecond()/1000000))), nil
 }
 
 // Deserialize @b, accept both `/Date(\d+)` and `\/Date(\d+)\/`
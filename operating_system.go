@@ -1,6 +1,10 @@
 package clcv1
 
 import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sort"
 	"fmt"
 )
 
@@ -10,48 +14,237 @@ import (
  */
 type OperatingSystem int
 
+// Operating system family.
+type OSFamily int
+
+const (
+	OtherOS OSFamily = iota
+	Windows
+	Linux
+	BSD
+	CoreOS
+)
+
+func (f OSFamily) String() string {
+	switch f {
+	case Windows: return "Windows"
+	case Linux:   return "Linux"
+	case BSD:     return "BSD"
+	case CoreOS:  return "CoreOS"
+	}
+	return "Other"
+}
+
+// Structured description of an OperatingSystem.
+type OSInfo struct {
+	// Display name, as used by CLC.
+	Name		string
+
+	// Operating system family.
+	Family		OSFamily
+
+	// Distribution, e.g. "Ubuntu" or "Windows Server".
+	Distribution	string
+
+	// Major version, e.g. "14" or "2012 R2" (empty if not specified).
+	Version		string
+
+	// Word size of the architecture: 32 or 64.
+	Bits		int
+
+	// Edition, e.g. "Enterprise" or "Datacenter" (Windows only, empty if not specified).
+	Edition		string
+}
+
+var osTable = map[OperatingSystem]OSInfo{
+	2:  { "Windows 2003 32-bit",               Windows, "Windows Server",  "2003",    32, ""           },
+	3:  { "Windows 2003 64-bit",               Windows, "Windows Server",  "2003",    64, ""           },
+	4:  { "Windows 2008 32-bit",               Windows, "Windows Server",  "2008",    32, ""           },
+	5:  { "Windows 2008 64-bit",               Windows, "Windows Server",  "2008",    64, ""           },
+	6:  { "CentOS 32-bit",                     Linux,   "CentOS",          "",        32, ""           },
+	7:  { "CentOS 64-bit",                     Linux,   "CentOS",          "",        64, ""           },
+	13: { "FreeBSD 32-bit",                    BSD,     "FreeBSD",         "",        32, ""           },
+	14: { "FreeBSD 64-bit",                    BSD,     "FreeBSD",         "",        64, ""           },
+	15: { "Windows 2003 Enterprise 32-bit",    Windows, "Windows Server",  "2003",    32, "Enterprise" },
+	16: { "Windows 2003 Enterprise 64-bit",    Windows, "Windows Server",  "2003",    64, "Enterprise" },
+	17: { "Windows 2008 Enterprise 32-bit",    Windows, "Windows Server",  "2008",    32, "Enterprise" },
+	18: { "Windows 2008 Enterprise 64-bit",    Windows, "Windows Server",  "2008",    64, "Enterprise" },
+	19: { "Ubuntu 32-bit",                     Linux,   "Ubuntu",          "",        32, ""           },
+	20: { "Ubuntu 64-bit",                     Linux,   "Ubuntu",          "",        64, ""           },
+	21: { "Debian 64-bit",                     Linux,   "Debian",          "",        64, ""           },
+	22: { "RedHat Enterprise Linux 64-bit",    Linux,   "RedHat",          "",        64, ""           },
+	24: { "Windows 2012 64-bit",               Windows, "Windows Server",  "2012",    64, ""           },
+	25: { "RedHat Enterprise Linux 5 64-bit",  Linux,   "RedHat",          "5",       64, ""           },
+	26: { "Windows 2008 Datacenter 64-bit",    Windows, "Windows Server",  "2008",    64, "Datacenter" },
+	27: { "Windows 2012 Datacenter 64-bit",    Windows, "Windows Server",  "2012",    64, "Datacenter" },
+	28: { "Windows 2012 R2 Datacenter 64-Bit", Windows, "Windows Server",  "2012 R2", 64, "Datacenter" },
+	29: { "Ubuntu 10 32-Bit",                  Linux,   "Ubuntu",          "10",      32, ""           },
+	30: { "Ubuntu 10 64-Bit",                  Linux,   "Ubuntu",          "10",      64, ""           },
+	31: { "Ubuntu 12 64-Bit",                  Linux,   "Ubuntu",          "12",      64, ""           },
+	32: { "CentOS 5 32-Bit",                   Linux,   "CentOS",          "5",       32, ""           },
+	33: { "CentOS 5 64-Bit",                   Linux,   "CentOS",          "5",       64, ""           },
+	34: { "CentOS 6 32-Bit",                   Linux,   "CentOS",          "6",       32, ""           },
+	35: { "CentOS 6 64-Bit",                   Linux,   "CentOS",          "6",       64, ""           },
+	36: { "Debian 6 64-Bit",                   Linux,   "Debian",          "6",       64, ""           },
+	37: { "Debian 7 64-Bit",                   Linux,   "Debian",          "7",       64, ""           },
+	38: { "RedHat 6 64-Bit",                   Linux,   "RedHat",          "6",       64, ""           },
+	39: { "CoreOS",                            CoreOS,  "CoreOS",          "",        64, ""           },
+	40: { "PXE Boot",                          OtherOS, "PXE Boot",        "",        64, ""           },
+	41: { "Ubuntu 14 64-Bit",                  Linux,   "Ubuntu",          "14",      64, ""           },
+	42: { "RedHat 7 64-Bit",                   Linux,   "RedHat",          "7",       64, ""           },
+	43: { "Windows 2008 R2 Standard 64-Bit",   Windows, "Windows Server",  "2008 R2", 64, "Standard"   },
+	44: { "Windows 2008 R2 Enterprise 64-Bit", Windows, "Windows Server",  "2008 R2", 64, "Enterprise" },
+	45: { "Windows 2008 R2 Datacenter 64-Bit", Windows, "Windows Server",  "2008 R2", 64, "Datacenter" },
+	46: { "Windows 2012 R2 Standard 64-bit",   Windows, "Windows Server",  "2012 R2", 64, "Standard"   },
+}
+
+// Return the structured description of @o, and whether @o is a known identifier.
+func (o OperatingSystem) Info() (OSInfo, bool) {
+	info, ok := osTable[o]
+	return info, ok
+}
+
 func (o OperatingSystem) String() string {
-	switch o {
-	case 2:  return "Windows 2003 32-bit"
-	case 3:  return "Windows 2003 64-bit"
-	case 4:  return "Windows 2008 32-bit"
-	case 5:  return "Windows 2008 64-bit"
-	case 6:  return "CentOS 32-bit"
-	case 7:  return "CentOS 64-bit"
-	case 13: return "FreeBSD 32-bit"
-	case 14: return "FreeBSD 64-bit"
-	case 15: return "Windows 2003 Enterprise 32-bit"
-	case 16: return "Windows 2003 Enterprise 64-bit"
-	case 17: return "Windows 2008 Enterprise 32-bit"
-	case 18: return "Windows 2008 Enterprise 64-bit"
-	case 19: return "Ubuntu 32-bit"
-	case 20: return "Ubuntu 64-bit"
-	case 21: return "Debian 64-bit"
-	case 22: return "RedHat Enterprise Linux 64-bit"
-	case 24: return "Windows 2012 64-bit"
-	case 25: return "RedHat Enterprise Linux 5 64-bit"
-	case 26: return "Windows 2008 Datacenter 64-bit"
-	case 27: return "Windows 2012 Datacenter 64-bit"
-	case 28: return "Windows 2012 R2 Datacenter 64-Bit"
-	case 29: return "Ubuntu 10 32-Bit"
-	case 30: return "Ubuntu 10 64-Bit"
-	case 31: return "Ubuntu 12 64-Bit"
-	case 32: return "CentOS 5 32-Bit"
-	case 33: return "CentOS 5 64-Bit"
-	case 34: return "CentOS 6 32-Bit"
-	case 35: return "CentOS 6 64-Bit"
-	case 36: return "Debian 6 64-Bit"
-	case 37: return "Debian 7 64-Bit"
-	case 38: return "RedHat 6 64-Bit"
-	case 39: return "CoreOS"
-	case 40: return "PXE Boot"
-	case 41: return "Ubuntu 14 64-Bit"
-	case 42: return "RedHat 7 64-Bit"
-	case 43: return "Windows 2008 R2 Standard 64-Bit"
-	case 44: return "Windows 2008 R2 Enterprise 64-Bit"
-	case 45: return "Windows 2008 R2 Datacenter 64-Bit"
-	case 46: return "Windows 2012 R2 Standard 64-bit"
-	default:
-		return fmt.Sprintf("Unknown OS %d", o)
+	if info, ok := osTable[o]; ok {
+		return info.Name
+	}
+	return fmt.Sprintf("Unknown OS %d", o)
+}
+
+// Return the family of @o (OtherOS if unknown).
+func (o OperatingSystem) Family() OSFamily {
+	return osTable[o].Family
+}
+
+// Return true if @o is a Windows operating system.
+func (o OperatingSystem) IsWindows() bool {
+	return o.Family() == Windows
+}
+
+// Return true if @o is a Linux operating system (including CoreOS).
+func (o OperatingSystem) IsLinux() bool {
+	return o.Family() == Linux || o.Family() == CoreOS
+}
+
+// Return true if @o is a 64-bit operating system.
+func (o OperatingSystem) Is64Bit() bool {
+	return osTable[o].Bits == 64
+}
+
+// Return the protocol used to log in to servers running @o ("RDP" or "SSH").
+func (o OperatingSystem) RemoteProtocol() string {
+	if o.IsWindows() {
+		return "RDP"
+	}
+	return "SSH"
+}
+
+// Return the name of the administrator/root account on servers running @o.
+func (o OperatingSystem) AdminUser() string {
+	if o.IsWindows() {
+		return "Administrator"
+	}
+	return "root"
+}
+
+// Split the operating system name @s into lower-case words, dropping the "bit" suffix of the
+// word size, e.g. "Ubuntu 14 64-Bit" -> [ubuntu 14 64].
+func osNameWords(s string) (words []string) {
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return r == ' ' || r == '-' || r == '\t' }) {
+		if w = strings.TrimSuffix(w, "bit"); w != "" {
+			words = append(words, w)
+		}
+	}
+	return
+}
+
+// Parse @s as the numeric identifier or the name of an operating system. Names are matched
+// case-insensitively by their words, in any order, where the words may be taken from the name,
+// family, distribution, version, edition and word size, e.g. "ubuntu 14 64" or "Windows Server 2012 R2
+// Standard". Of several matching systems, the one whose name has the fewest words not given in @s is
+// returned; it is an error if that is ambiguous, e.g. "ubuntu 10" (32-bit or 64-bit).
+func ParseOperatingSystem(s string) (OperatingSystem, error) {
+	var want  = osNameWords(s)
+	var best  []OperatingSystem
+	var extra = -1
+
+	if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+		return OperatingSystem(n), nil
+	} else if len(want) == 0 {
+		return 0, fmt.Errorf("Empty operating system name")
+	}
+
+	for id, info := range osTable {
+		var have = make(map[string]bool)
+		var name = osNameWords(info.Name)
+		var n    = len(name)
+
+		for _, w := range append(name, osNameWords(fmt.Sprintf("%s %s %s %s %d", info.Family,
+				  info.Distribution, info.Version, info.Edition, info.Bits))...) {
+			have[w] = true
+		}
+		for _, w := range want {
+			if !have[w] {
+				n = -1
+				break
+			}
+		}
+		if n < 0 {
+			continue
+		}
+		for _, w := range name {
+			for _, g := range want {
+				if g == w {
+					n--
+					break
+				}
+			}
+		}
+
+		if extra < 0 || n < extra {
+			best, extra = []OperatingSystem{ id }, n
+		} else if n == extra {
+			best = append(best, id)
+		}
+	}
+
+	switch len(best) {
+	case 0:
+		return 0, fmt.Errorf("Unknown operating system %q", s)
+	case 1:
+		return best[0], nil
+	}
+
+	var names []string
+	sort.Slice(best, func(i, j int) bool { return best[i] < best[j] })
+	for _, id := range best {
+		names = append(names, osTable[id].Name)
+	}
+	return 0, fmt.Errorf("Ambiguous operating system %q (matches %s)", s, strings.Join(names, ", "))
+}
+
+// Marshal known identifiers as readable name, unknown ones as number.
+func (o OperatingSystem) MarshalJSON() ([]byte, error) {
+	if info, ok := osTable[o]; ok {
+		return json.Marshal(info.Name)
+	}
+	return json.Marshal(int(o))
+}
+
+// Accept both the numeric identifier (as returned by the API) and the name.
+func (o *OperatingSystem) UnmarshalJSON(b []byte) error {
+	var name string
+
+	if err := json.Unmarshal(b, (*int)(o)); err == nil {
+		return nil
+	} else if err := json.Unmarshal(b, &name); err != nil {
+		return fmt.Errorf("Invalid operating system value %s", string(b))
+	}
+
+	os, err := ParseOperatingSystem(name)
+	if err != nil {
+		return err
 	}
+	*o = os
+	return nil
 }
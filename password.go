@@ -0,0 +1,185 @@
+/*
+ * Server password policy: client-side checks and generation of passwords for CreateServer,
+ * ConvertTemplateToServer, ConvertServerToTemplate and ServerChangePassword, which reject
+ * passwords that do not meet the (undocumented) strength requirements with StatusCode 1414.
+ */
+package clcv1
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"fmt"
+)
+
+// Character classes used by the password strength policy.
+const (
+	pwUpper   = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	pwLower   = "abcdefghijkmnopqrstuvwxyz"
+	pwDigits  = "23456789"
+	pwSpecial = "!@#$%^&*()_-+=[]{}|;:,.<>?/~"
+)
+
+// Default length of generated passwords.
+const DefaultPasswordLength = 16
+
+// Password requirements. The v1 API does not document its password strength policy (it only reports
+// StatusCode 1414); the predefined policies below are conservative client-side defaults.
+// Check enforces only MinLength and MinClasses; MaxLength and Disallowed shape generated passwords,
+// so that these avoid characters that are likely to cause trouble on the target OS.
+type PasswordPolicy struct {
+	// Descriptive name of the policy.
+	Name		string
+
+	// Minimum password length.
+	MinLength	int
+
+	// Maximum length of generated passwords.
+	MaxLength	int
+
+	// Minimum number of character classes (upper case, lower case, digits, special characters).
+	MinClasses	int
+
+	// Characters that generated passwords do not contain (nor white space or non-ASCII characters).
+	Disallowed	string
+}
+
+var (
+	// Policy for Linux/BSD servers. Generated passwords avoid quotes and shell meta-characters.
+	UnixPasswordPolicy = PasswordPolicy{
+		Name:       "Linux",
+		MinLength:  8,
+		MaxLength:  64,
+		MinClasses: 3,
+		Disallowed: "\"'`\\$&|;<>",
+	}
+
+	// Policy for Windows servers. Generated passwords avoid quotes and characters with
+	// special meaning to the Windows command processor.
+	WindowsPasswordPolicy = PasswordPolicy{
+		Name:       "Windows",
+		MinLength:  8,
+		MaxLength:  64,
+		MinClasses: 3,
+		Disallowed: "\"'`\\%^&|<>",
+	}
+
+	// Policy that satisfies both of the above, for use when the operating system is not known.
+	DefaultPasswordPolicy = PasswordPolicy{
+		Name:       "Default",
+		MinLength:  8,
+		MaxLength:  64,
+		MinClasses: 3,
+		Disallowed: "\"'`\\$%^&|;<>",
+	}
+)
+
+// Return the password policy that applies to servers running @os.
+// DefaultPasswordPolicy is returned if @os is not a known operating system.
+func PasswordPolicyFor(os OperatingSystem) *PasswordPolicy {
+	if _, ok := os.Info(); !ok {
+		return &DefaultPasswordPolicy
+	} else if os.IsWindows() {
+		return &WindowsPasswordPolicy
+	}
+	return &UnixPasswordPolicy
+}
+
+// Check the length and character classes of @pass against @p. Returns a ValidationError listing all
+// violations. MaxLength and Disallowed are not enforced, since the API may accept such passwords.
+// The error messages never include the password itself.
+func (p *PasswordPolicy) Check(pass string) error {
+	var v ValidationError
+	var upper, lower, digit, special int
+
+	if len(pass) < p.MinLength {
+		v.add("Password is too short (minimum length is %d)", p.MinLength)
+	}
+
+	for _, r := range pass {
+		switch {
+		case r >= 'A' && r <= 'Z': upper = 1
+		case r >= 'a' && r <= 'z': lower = 1
+		case r >= '0' && r <= '9': digit = 1
+		default:                   special = 1
+		}
+	}
+
+	if classes := upper + lower + digit + special; classes < p.MinClasses {
+		v.add("Password must contain characters from at least %d of: upper case, lower case, digits, special characters", p.MinClasses)
+	}
+	return v.err()
+}
+
+// Generate a random password of @length characters (DefaultPasswordLength if 0) that satisfies @p.
+// The password contains at least one character of each class.
+func (p *PasswordPolicy) Generate(length int) (string, error) {
+	var classes []string
+
+	if length == 0 {
+		length = DefaultPasswordLength
+	}
+	if length < p.MinLength || length > p.MaxLength {
+		return "", fmt.Errorf("Password length %d is outside of the %s policy range %d..%d",
+				      length, p.Name, p.MinLength, p.MaxLength)
+	}
+
+	for _, set := range []string{ pwUpper, pwLower, pwDigits, pwSpecial } {
+		set = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(p.Disallowed, r) {
+				return -1
+			}
+			return r
+		}, set)
+		if set != "" {
+			classes = append(classes, set)
+		}
+	}
+	if len(classes) < p.MinClasses || length < len(classes) {
+		return "", fmt.Errorf("Unable to generate a password for the %s policy", p.Name)
+	}
+	return generatePassword(length, classes)
+}
+
+// Generate a random password of @length characters that contains at least one character of each of
+// the character sets @classes, and otherwise characters drawn from all of them.
+func generatePassword(length int, classes []string) (string, error) {
+	var all = strings.Join(classes, "")
+
+	if length < len(classes) {
+		return "", fmt.Errorf("Password length %d is too short", length)
+	}
+
+	pass := make([]byte, length)
+	for i := range pass {
+		var set = all
+
+		if i < len(classes) {
+			set = classes[i]
+		}
+		c, err := randomChar(set)
+		if err != nil {
+			return "", err
+		}
+		pass[i] = c
+	}
+
+	/* Shuffle, so that the leading characters are not predictable by class */
+	for i := len(pass) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i + 1)))
+		if err != nil {
+			return "", fmt.Errorf("Failed to generate random number: %s", err)
+		}
+		pass[i], pass[j.Int64()] = pass[j.Int64()], pass[i]
+	}
+	return string(pass), nil
+}
+
+// Pick a random character from @set.
+func randomChar(set string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+	if err != nil {
+		return 0, fmt.Errorf("Failed to generate random number: %s", err)
+	}
+	return set[n.Int64()], nil
+}
@@ -0,0 +1,415 @@
+/*
+ * Scheduled power management of servers and hardware groups.
+ */
+package clcv1
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"sort"
+	"sync"
+	"time"
+	"fmt"
+	"os"
+)
+
+// Power operation that a schedule performs.
+type PowerAction int
+
+const (
+	PowerOn PowerAction = iota + 1
+	PowerShutdown
+)
+
+func (a PowerAction) String() string {
+	switch a {
+	case PowerOn:       return "on"
+	case PowerShutdown: return "shutdown"
+	}
+	return fmt.Sprintf("Unknown power action %d", int(a))
+}
+
+// Parse the string representation of a PowerAction.
+func ParsePowerAction(s string) (PowerAction, error) {
+	for _, a := range []PowerAction{ PowerOn, PowerShutdown } {
+		if strings.EqualFold(s, a.String()) {
+			return a, nil
+		}
+	}
+	return 0, fmt.Errorf("Invalid power action %q (expected on or shutdown)", s)
+}
+
+func (a PowerAction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+func (a *PowerAction) UnmarshalJSON(b []byte) (err error) {
+	var s string
+
+	if err = json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("Invalid power action %s", string(b))
+	}
+	*a, err = ParsePowerAction(s)
+	return
+}
+
+// A single power schedule, e.g. { "Target": "WA1/Default Group/Dev", "Schedule": "0 19 * * mon-fri", "Action": "shutdown" }.
+type PowerSchedule struct {
+	// Server name, or group path of the form "<location>/<group>/..." (see ResolveGroupPath).
+	Target		string
+
+	// Cron-style schedule (see ParseCronSchedule), evaluated in local time.
+	Schedule	string
+
+	// What to do when the schedule fires.
+	Action		PowerAction
+
+	cron		*CronSchedule
+}
+
+// Return true if @p applies to a group rather than a single server.
+func (p *PowerSchedule) IsGroup() bool {
+	return strings.Contains(p.Target, "/")
+}
+
+// Key identifying @p in the scheduler state.
+func (p *PowerSchedule) key() string {
+	return fmt.Sprintf("%s|%s|%s", p.Target, p.Schedule, p.Action)
+}
+
+func (p *PowerSchedule) String() string {
+	return fmt.Sprintf("%s %s at %q", p.Action, p.Target, p.Schedule)
+}
+
+// Load power schedules from JSON file @path, which contains an array of PowerSchedule.
+func LoadPowerSchedules(path string) (schedules []PowerSchedule, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read power schedules: %s", err)
+	} else if err = json.Unmarshal(data, &schedules); err != nil {
+		return nil, fmt.Errorf("Failed to decode power schedules in %s: %s", path, err)
+	}
+	return
+}
+
+// Outcome of a power action on one server or group.
+type PowerEvent struct {
+	// The schedule that fired.
+	Schedule	*PowerSchedule
+
+	// When the schedule was due (may be earlier than the time of the action, if it was missed).
+	Due		time.Time
+
+	// The server, or group path, the action applied to.
+	Target		string
+
+	// Servers that were skipped, with the reason.
+	Skipped		map[string]string
+
+	// Request ID of the power operation (0 if nothing was done).
+	RequestID	int
+
+	// Error, if the action failed.
+	Err		error
+}
+
+// Persisted state of the scheduler: when each schedule was last handled.
+type powerSchedulerState struct {
+	LastRun		map[string]time.Time
+}
+
+// PowerScheduler runs power schedules, issuing ShutdownServer/PowerOnServer and the group equivalents.
+type PowerScheduler struct {
+	client		*Client
+
+	// The alias of the account that owns the servers (optional).
+	acctAlias	string
+
+	// Schedules to run.
+	schedules	[]PowerSchedule
+
+	// Windows missed by more than this (e.g. while the scheduler was not running) are skipped.
+	MaxCatchUp	time.Duration
+
+	// How often to check on power requests (defaults to 5 seconds).
+	PollInterval	time.Duration
+
+	// How long to wait for each power request (defaults to DefaultRequestTimeout).
+	Timeout		time.Duration
+
+	// Maximum number of servers to power concurrently, if a group has to be handled
+	// server by server (defaults to 4).
+	Parallel	int
+
+	// File to persist the state in (may be empty to keep state in memory only).
+	stateFile	string
+
+	state		powerSchedulerState
+}
+
+// Set up a scheduler for @schedules.
+// @acctAlias: The alias of the account that owns the servers (optional).
+// @stateFile: file to load/save the state from/to; if empty, no state is persisted.
+// If @stateFile exists, windows that were missed while the scheduler was not running are
+// caught up on (up to MaxCatchUp, 24 hours by default). Without saved state, only windows
+// after the first Run are handled.
+func (c *Client) NewPowerScheduler(schedules []PowerSchedule, acctAlias, stateFile string) (*PowerScheduler, error) {
+	var s = &PowerScheduler{
+		client:     c,
+		acctAlias:  acctAlias,
+		MaxCatchUp: 24 * time.Hour,
+		stateFile:  stateFile,
+		state:      powerSchedulerState{ LastRun: make(map[string]time.Time) },
+	}
+
+	for _, p := range schedules {
+		if p.Target == "" {
+			return nil, fmt.Errorf("Power schedule %q has no target", p.Schedule)
+		} else if p.Action != PowerOn && p.Action != PowerShutdown {
+			return nil, fmt.Errorf("Power schedule for %s has no valid action", p.Target)
+		}
+		cron, err := ParseCronSchedule(p.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("Power schedule for %s: %s", p.Target, err)
+		}
+		p.cron = cron
+		s.schedules = append(s.schedules, p)
+	}
+
+	if stateFile != "" {
+		if data, err := ioutil.ReadFile(stateFile); err == nil {
+			if err = json.Unmarshal(data, &s.state); err != nil {
+				return nil, fmt.Errorf("Failed to decode power scheduler state in %s: %s", stateFile, err)
+			} else if s.state.LastRun == nil {
+				s.state.LastRun = make(map[string]time.Time)
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("Failed to read power scheduler state: %s", err)
+		}
+	}
+	return s, nil
+}
+
+// Return the time the next schedule is due after @t (zero if none).
+func (s *PowerScheduler) NextDue(t time.Time) (next time.Time) {
+	for i := range s.schedules {
+		if n := s.schedules[i].cron.Next(t); !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+	return
+}
+
+// Handle all schedules that are due at @now, passing each outcome to @report (if non-nil).
+// If several schedules of the same target are due (e.g. after a restart that missed both the
+// evening shutdown and the morning power-on), only the most recent one is performed.
+// A window counts as handled only once its action succeeded; failed windows remain due, and are
+// retried on the next call (subject to MaxCatchUp).
+func (s *PowerScheduler) RunOnce(now time.Time, report func(*PowerEvent)) error {
+	var due     = make(map[string]*PowerEvent)
+	var keys    = make(map[string][]string)
+	var targets []string
+
+	for i := range s.schedules {
+		var p = &s.schedules[i]
+		var key = p.key()
+
+		last, ok := s.state.LastRun[key]
+		if !ok {
+			/* New schedule: do not act on windows before it was first seen */
+			s.state.LastRun[key] = now
+			continue
+		}
+
+		if s.MaxCatchUp > 0 && last.Before(now.Add(-s.MaxCatchUp)) {
+			last = now.Add(-s.MaxCatchUp)
+		}
+		when := p.cron.Last(last, now)
+		if when.IsZero() {
+			s.state.LastRun[key] = now
+			continue
+		}
+
+		target := strings.ToUpper(p.Target)
+		keys[target] = append(keys[target], key)
+		if ev, ok := due[target]; !ok {
+			targets = append(targets, target)
+			due[target] = &PowerEvent{ Schedule: p, Due: when, Target: p.Target }
+		} else if when.After(ev.Due) {
+			ev.Schedule, ev.Due = p, when
+		}
+	}
+
+	for _, target := range targets {
+		ev := due[target]
+		if ev.Schedule.IsGroup() {
+			ev.RequestID, ev.Err = s.runGroup(ev)
+		} else {
+			ev.RequestID, ev.Err = s.runServer(ev)
+		}
+		if ev.Err == nil {
+			/* Also covers the schedules of @target that were superseded by @ev */
+			for _, key := range keys[target] {
+				s.state.LastRun[key] = now
+			}
+		}
+		if report != nil {
+			report(ev)
+		}
+	}
+	return s.save()
+}
+
+// Run the schedules until @stop is closed, passing each outcome to @report (if non-nil).
+func (s *PowerScheduler) Run(stop <-chan struct{}, report func(*PowerEvent)) error {
+	for {
+		if err := s.RunOnce(time.Now(), report); err != nil {
+			return err
+		}
+
+		wait := time.Minute
+		if next := s.NextDue(time.Now()); !next.IsZero() && time.Until(next) < wait {
+			wait = time.Until(next)
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Return a reason to skip powering @server, or "" if the action should be performed.
+func powerSkipReason(server *Server, action PowerAction) string {
+	switch {
+	case server.IsTemplate:
+		return "template"
+	case server.InMaintenanceMode:
+		return "in maintenance mode"
+	case powerSatisfied(server, action):
+		return "already " + strings.ToLower(server.PowerState.String())
+	}
+	return ""
+}
+
+// Return true if @server is already in the state that @action leads to.
+func powerSatisfied(server *Server, action PowerAction) bool {
+	return (action == PowerOn && server.PowerState == PowerStarted) ||
+	       (action == PowerShutdown && server.PowerState == PowerStopped)
+}
+
+// Perform @ev on a single server.
+func (s *PowerScheduler) runServer(ev *PowerEvent) (reqId int, err error) {
+	server, err := s.client.GetServer(ev.Target, s.acctAlias)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to look up server %s: %s", ev.Target, err)
+	} else if reason := powerSkipReason(&server, ev.Schedule.Action); reason != "" {
+		ev.Skipped = map[string]string{ server.Name: reason }
+		return 0, nil
+	}
+	return s.powerServer(&server, ev.Schedule.Action)
+}
+
+// Perform @action on @server, and wait for it to complete.
+func (s *PowerScheduler) powerServer(server *Server, action PowerAction) (reqId int, err error) {
+	if action == PowerOn {
+		reqId, err = s.client.PowerOnServer(server.Name, s.acctAlias)
+	} else {
+		reqId, err = s.client.ShutdownServer(server.Name, s.acctAlias)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("Failed to %s %s: %s", action, server.Name, err)
+	}
+	_, err = s.client.WaitForRequest(reqId, s.PollInterval, s.Timeout)
+	return
+}
+
+// Perform @ev on all servers of a group (including sub-groups); templates are ignored.
+// Servers that are already in the target state count as done. The group operation is used unless
+// some servers have to be skipped (maintenance mode); the others are then handled one by one,
+// up to Parallel at a time.
+func (s *PowerScheduler) runGroup(ev *PowerEvent) (reqId int, err error) {
+	var todo    []*Server
+	var blocked bool
+
+	group, err := s.client.LookupGroupNode(ev.Target, "", s.acctAlias, true)
+	if err != nil {
+		return 0, err
+	}
+
+	ev.Skipped = make(map[string]string)
+	for _, srv := range group.AllServers(false) {
+		if reason := powerSkipReason(srv, ev.Schedule.Action); reason == "" {
+			todo = append(todo, srv)
+		} else {
+			ev.Skipped[srv.Name] = reason
+			blocked = blocked || !powerSatisfied(srv, ev.Schedule.Action)
+		}
+	}
+
+	if len(todo) == 0 {
+		return 0, nil
+	} else if !blocked {
+		if ev.Schedule.Action == PowerOn {
+			reqId, err = s.client.PowerOnHardwareGroup(group.UUID, s.acctAlias)
+		} else {
+			reqId, err = s.client.ShutdownHardwareGroup(group.UUID, s.acctAlias)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("Failed to %s group %s: %s", ev.Schedule.Action, ev.Target, err)
+		}
+		_, err = s.client.WaitForRequest(reqId, s.PollInterval, s.Timeout)
+		return
+	}
+
+	var errs []string
+	var mu   sync.Mutex
+	var wg   sync.WaitGroup
+
+	parallel := s.Parallel
+	if parallel < 1 {
+		parallel = 4
+	}
+	sem := make(chan struct{}, parallel)
+
+	for _, srv := range todo {
+		wg.Add(1)
+		go func(srv *Server) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if _, err := s.powerServer(srv, ev.Schedule.Action); err != nil {
+				mu.Lock()
+				errs = append(errs, err.Error())
+				mu.Unlock()
+			}
+		}(srv)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return 0, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return 0, nil
+}
+
+// Persist the state of @s, if a state file was configured.
+func (s *PowerScheduler) save() error {
+	if s.stateFile == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(&s.state)
+	if err != nil {
+		return fmt.Errorf("Failed to encode power scheduler state: %s", err)
+	}
+
+	/* Write to a temporary file first, so that an interrupted write does not lose the state */
+	if err = ioutil.WriteFile(s.stateFile + ".tmp", data, 0600); err != nil {
+		return fmt.Errorf("Failed to save power scheduler state: %s", err)
+	}
+	return os.Rename(s.stateFile + ".tmp", s.stateFile)
+}
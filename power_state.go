@@ -0,0 +1,167 @@
+/*
+ * Idempotent transitions of servers and hardware groups to a desired power state.
+ */
+package clcv1
+
+import (
+	"sync"
+	"sort"
+	"time"
+	"fmt"
+)
+
+// Outcome of EnsurePowerState for a single server.
+type PowerChange struct {
+	// The name of the server.
+	Server		string
+
+	// Power state before and after.
+	From, To	PowerState
+
+	// Operations that were performed, in order, e.g. "PowerOn", "Shutdown" (empty if already in state @To).
+	Steps		[]string
+
+	// Error, if a transition failed.
+	Err		error
+}
+
+// Return true if @p changed the server.
+func (p *PowerChange) Changed() bool {
+	return len(p.Steps) > 0
+}
+
+// Options for EnsurePowerState and EnsureGroupPowerState.
+type PowerStateOptions struct {
+	// Use PowerOffServer (hard power-off) instead of ShutdownServer (graceful) to stop servers.
+	Hard		bool
+
+	// Maximum number of servers of a group to handle concurrently (at least 1).
+	Parallel	int
+
+	// How often to check on power requests (defaults to 5 seconds).
+	PollInterval	time.Duration
+
+	// How long to wait for each power request (defaults to DefaultRequestTimeout).
+	Timeout		time.Duration
+}
+
+// Operations needed to go from @from to @to, in order.
+// A paused server is resumed (powered on) before shutting it down, and a stopped
+// server is powered on before pausing it.
+func powerSteps(from, to PowerState, hard bool) (steps []string) {
+	var stop = "Shutdown"
+
+	if hard {
+		stop = "PowerOff"
+	}
+	switch to {
+	case PowerStarted:
+		if from != PowerStarted {
+			steps = []string{ "PowerOn" }
+		}
+	case PowerStopped:
+		switch from {
+		case PowerStarted: steps = []string{ stop }
+		case PowerPaused:  steps = []string{ "PowerOn", stop }
+		}
+	case PowerPaused:
+		switch from {
+		case PowerStarted: steps = []string{ "Pause" }
+		case PowerStopped: steps = []string{ "PowerOn", "Pause" }
+		}
+	}
+	return
+}
+
+// Run a single power operation @step on server @name, and wait for it to complete.
+func (c *Client) powerStep(name, acctAlias, step string, opts *PowerStateOptions) (err error) {
+	var reqId int
+
+	switch step {
+	case "PowerOn":  reqId, err = c.PowerOnServer(name, acctAlias)
+	case "PowerOff": reqId, err = c.PowerOffServer(name, acctAlias)
+	case "Shutdown": reqId, err = c.ShutdownServer(name, acctAlias)
+	case "Pause":    reqId, err = c.PauseServer(name, acctAlias)
+	default:         return fmt.Errorf("Unsupported power operation %q", step)
+	}
+	if err == nil {
+		_, err = c.WaitForRequest(reqId, opts.PollInterval, opts.Timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%s of %s failed: %s", step, name, err)
+	}
+	return nil
+}
+
+// Bring server @name into power state @want, performing only the necessary operations.
+// @acctAlias: The alias of the account that owns the server (optional).
+func (c *Client) EnsurePowerState(name, acctAlias string, want PowerState, opts PowerStateOptions) *PowerChange {
+	server, err := c.GetServer(name, acctAlias)
+	if err != nil {
+		return &PowerChange{ Server: name, To: want, Err: fmt.Errorf("Failed to look up server %s: %s", name, err) }
+	}
+	return c.ensurePowerState(&server, acctAlias, want, &opts)
+}
+
+// Bring @server into power state @want.
+func (c *Client) ensurePowerState(server *Server, acctAlias string, want PowerState, opts *PowerStateOptions) *PowerChange {
+	var change = &PowerChange{ Server: server.Name, From: server.PowerState, To: want }
+
+	if !want.Valid() {
+		change.Err = fmt.Errorf("Invalid power state %q", want)
+		return change
+	} else if !server.PowerState.Valid() {
+		change.Err = fmt.Errorf("Server %s is in an unsupported power state %q", server.Name, server.PowerState)
+		return change
+	}
+
+	for _, step := range powerSteps(change.From, want, opts.Hard) {
+		if change.Err = c.powerStep(server.Name, acctAlias, step, opts); change.Err != nil {
+			break
+		}
+		change.Steps = append(change.Steps, step)
+	}
+	return change
+}
+
+// Bring all servers of hardware group @ref (including sub-groups) into power state @want.
+// @ref:       The group UUID, name or path (see LookupGroupNode).
+// @location:  The data center location of the group (not needed if @ref is a path).
+// @acctAlias: The alias of the account that owns the group (optional).
+// Servers that are already in state @want are left alone; templates are skipped.
+// The changes are returned sorted by server name.
+func (c *Client) EnsureGroupPowerState(ref, location, acctAlias string, want PowerState, opts PowerStateOptions) ([]*PowerChange, error) {
+	var wg sync.WaitGroup
+
+	if !want.Valid() {
+		return nil, fmt.Errorf("Invalid power state %q", want)
+	}
+
+	group, err := c.LookupGroupNode(ref, location, acctAlias, true)
+	if err != nil {
+		return nil, err
+	}
+
+	servers := group.AllServers(false)
+
+	if opts.Parallel < 1 {
+		opts.Parallel = 1
+	}
+	sem := make(chan struct{}, opts.Parallel)
+
+	changes := make([]*PowerChange, len(servers))
+	for i := range servers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			changes[i] = c.ensurePowerState(servers[i], acctAlias, want, &opts)
+		}(i)
+	}
+	wg.Wait()
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Server < changes[j].Server })
+	return changes, nil
+}
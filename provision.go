@@ -0,0 +1,105 @@
+/*
+ * Server provisioning that waits for the deployment and returns the new Server records.
+ */
+package clcv1
+
+import (
+	"time"
+	"fmt"
+)
+
+// Default interval between deployment status queries.
+const deploymentPollInterval = 10 * time.Second
+
+// A newly provisioned server.
+type ProvisionedServer struct {
+	Server
+
+	// Administrator/root credentials of the server (only if requested).
+	Credentials	*ServerCredentials
+}
+
+// Wait for the deployment request @reqId to finish (see WaitForRequest), checking its status every
+// @pollInterval (10 seconds if 0), and giving up after @timeout (DefaultRequestTimeout if 0).
+// Returns the final deployment status; @err is set if the deployment failed or timed out. The status
+// is also returned in that case (if available), since a failed deployment may still have created servers.
+// @acctAlias, @location: as per GetDeploymentStatus.
+func (c *Client) WaitForDeployment(reqId int, acctAlias, location string, pollInterval, timeout time.Duration) (status *DeploymentStatus, err error) {
+	if pollInterval <= 0 {
+		pollInterval = deploymentPollInterval
+	}
+
+	qreq, err := c.WaitForRequest(reqId, pollInterval, timeout)
+	if qreq == nil {
+		return nil, err
+	}
+
+	status, serr := c.GetDeploymentStatus(reqId, acctAlias, location)
+	if err != nil {
+		return status, err
+	} else if serr != nil {
+		return nil, fmt.Errorf("Failed to query deployment status of request ID %d: %s", reqId, serr)
+	}
+	return status, nil
+}
+
+// Wait for the deployment request @reqId to finish, and look up the servers it created.
+// @acctAlias, @location: as per GetDeploymentStatus.
+// @withCreds: whether to also fetch the credentials of each server.
+func (c *Client) WaitForServers(reqId int, acctAlias, location string, withCreds bool) (servers []ProvisionedServer, err error) {
+	status, err := c.WaitForDeployment(reqId, acctAlias, location, 0, 0)
+	if err != nil {
+		return nil, err
+	} else if len(status.Servers) == 0 {
+		return nil, fmt.Errorf("Deployment request ID %d did not report any server names", reqId)
+	}
+
+	for _, name := range status.Servers {
+		var p ProvisionedServer
+
+		if p.Server, err = c.GetServer(name, acctAlias); err != nil {
+			return servers, fmt.Errorf("Failed to look up new server %s: %s", name, err)
+		}
+		if withCreds {
+			creds, err := c.GetServerCredentials(name, acctAlias)
+			if err != nil {
+				return servers, fmt.Errorf("Failed to get credentials of new server %s: %s", name, err)
+			}
+			p.Credentials = &creds
+		}
+		servers = append(servers, p)
+	}
+	return
+}
+
+// Create a server as per @req, wait for the deployment to complete, and return the new server.
+// @withCreds: whether to also fetch the credentials of the server.
+func (c *Client) CreateServerAndWait(req *CreateServerReq, withCreds bool) (*ProvisionedServer, error) {
+	reqId, err := c.CreateServer(req)
+	if err != nil {
+		return nil, err
+	}
+
+	servers, err := c.WaitForServers(reqId, req.AccountAlias, req.LocationAlias, withCreds)
+	if err != nil {
+		return nil, err
+	}
+	return &servers[0], nil
+}
+
+// Convert template @name into a server, wait for the deployment to complete, and return the new server.
+// @name, @password, @hwGrpUUID, @network, @acctAlias: as per ConvertTemplateToServer.
+// @location:  The data center location of the template (optional).
+// @withCreds: whether to also fetch the credentials of the server.
+func (c *Client) ConvertTemplateToServerAndWait(name, password, hwGrpUUID, network, acctAlias, location string, withCreds bool) (*ProvisionedServer, error) {
+	reqId, err := c.ConvertTemplateToServer(name, password, hwGrpUUID, network, acctAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	servers, err := c.WaitForServers(reqId, acctAlias, location, withCreds)
+	if err != nil {
+		return nil, err
+	}
+	return &servers[0], nil
+}
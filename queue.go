@@ -2,6 +2,8 @@ package clcv1
 
 import (
 	"github.com/grrtrr/clcv1/microsoft"
+	"time"
+	"fmt"
 )
 
 type ItemStatus int
@@ -57,3 +59,42 @@ func (c *Client) GetRequestStatus(requestID int) (qreq *QueueRequest, err error)
 	} { RequestDetails: qreq })
 	return
 }
+
+// Default for the @timeout argument of WaitForRequest and WaitForDeployment.
+const DefaultRequestTimeout = 2 * time.Hour
+
+// Wait for the long-running request @requestID to finish, checking its status every @pollInterval
+// (5 seconds if 0), and giving up after @timeout (DefaultRequestTimeout if 0).
+// Returns the final status of the request; @err is set if the request failed or timed out
+// (the request itself continues to run on the CLC side in the latter case).
+func (c *Client) WaitForRequest(requestID int, pollInterval, timeout time.Duration) (qreq *QueueRequest, err error) {
+	if pollInterval <= 0 {
+		pollInterval = 5 * time.Second
+	}
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		if qreq, err = c.GetRequestStatus(requestID); err != nil {
+			return nil, fmt.Errorf("Failed to query status of request ID %d: %s", requestID, err)
+		}
+
+		switch qreq.CurrentStatus {
+		case "Succeeded":
+			return qreq, nil
+		case "Failed":
+			return qreq, fmt.Errorf("Request ID %d (%s) failed: %s", requestID, qreq.RequestTitle, qreq.ProgressDesc)
+		}
+
+		if remaining := deadline.Sub(time.Now()); remaining <= 0 {
+			return qreq, fmt.Errorf("Timed out after %s waiting for request ID %d (%s, status %s)",
+						timeout, requestID, qreq.RequestTitle, qreq.CurrentStatus)
+		} else if remaining < pollInterval {
+			time.Sleep(remaining)
+		} else {
+			time.Sleep(pollInterval)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+/*
+ * Search and bulk restore of archived servers.
+ */
+package clcv1
+
+import (
+	"strings"
+	"path"
+	"sync"
+	"sort"
+	"time"
+	"fmt"
+)
+
+// An archived server, together with its data center.
+type ArchivedServerAt struct {
+	ArchivedServer
+
+	// The data center location of the archived server.
+	Location	string
+}
+
+// Search the archived servers at @locations (all locations if empty) for names matching @pattern.
+// @pattern:   Shell pattern as used by path.Match, e.g. "WA1ACCTWEB*" (case-insensitive).
+// @acctAlias: The alias of the account that owns the servers (optional).
+// If some locations could not be searched, they are reported via @err, along with the matches
+// found at the other locations.
+func (c *Client) FindArchivedServers(pattern, acctAlias string, locations []string) (res []ArchivedServerAt, err error) {
+	pattern = strings.ToUpper(pattern)
+	if _, err = path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("Invalid server name pattern %q: %s", pattern, err)
+	}
+
+	if len(locations) == 0 {
+		all, err := c.GetLocations()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list locations: %s", err)
+		}
+		for _, l := range all {
+			locations = append(locations, l.Alias)
+		}
+	}
+
+	var errs []string
+	for _, location := range locations {
+		servers, err := c.ListArchiveServers(acctAlias, location)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", location, err))
+			continue
+		}
+		for _, s := range servers {
+			if ok, _ := path.Match(pattern, strings.ToUpper(s.Name)); ok {
+				res = append(res, ArchivedServerAt{ ArchivedServer: s, Location: location })
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+
+	if len(errs) > 0 {
+		err = fmt.Errorf("Failed to list archived servers at %d location(s): %s", len(errs), strings.Join(errs, "; "))
+	}
+	return
+}
+
+// Options for RestoreServers.
+type RestoreOptions struct {
+	// Power on each server after it has been restored.
+	PowerOn		bool
+
+	// Maximum number of servers to restore concurrently (at least 1).
+	Parallel	int
+
+	// How often to check on the restore/power-on requests (defaults to 5 seconds).
+	PollInterval	time.Duration
+
+	// How long to wait for each restore/power-on request (defaults to DefaultRequestTimeout).
+	Timeout		time.Duration
+}
+
+// Outcome of restoring a single archived server.
+type RestoreResult struct {
+	// The name of the server.
+	Server		string
+
+	// The request ID of the restore request (0 if it was not submitted).
+	RequestID	int
+
+	// Whether the server was powered on after the restore.
+	PoweredOn	bool
+
+	// Error, if the restore or the power-on failed.
+	Err		error
+}
+
+// Restore the archived @servers (see FindArchivedServers) into the hardware group identified by
+// @groupPath, e.g. "WA1/Default Group/Restored", and wait for each restore to complete.
+// @acctAlias: The alias of the account that owns the servers (optional).
+// Servers can only be restored within their data center; if any of @servers is at a different
+// location than the group, an error is returned without restoring anything.
+// The results are returned in the order of @servers.
+func (c *Client) RestoreServers(servers []ArchivedServerAt, groupPath, acctAlias string, opts RestoreOptions) ([]RestoreResult, error) {
+	var wg sync.WaitGroup
+	var v  ValidationError
+
+	group, err := c.ResolveGroupPath(groupPath, acctAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range servers {
+		if !strings.EqualFold(s.Location, group.Location) {
+			v.add("Server %s is archived at %s, not at %s", s.Name, s.Location, group.Location)
+		}
+	}
+	if err = v.err(); err != nil {
+		return nil, err
+	}
+
+	if opts.Parallel < 1 {
+		opts.Parallel = 1
+	}
+	sem := make(chan struct{}, opts.Parallel)
+
+	results := make([]RestoreResult, len(servers))
+	for i, s := range servers {
+		wg.Add(1)
+		go func(res *RestoreResult, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			res.Server = name
+			res.Err    = c.restoreServer(res, group.UUID, acctAlias, &opts)
+		}(&results[i], s.Name)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// Restore @res.Server into the group @hwGrpUUID, filling in the remaining fields of @res.
+func (c *Client) restoreServer(res *RestoreResult, hwGrpUUID, acctAlias string, opts *RestoreOptions) (err error) {
+	if res.RequestID, err = c.RestoreServer(res.Server, acctAlias, hwGrpUUID); err != nil {
+		return fmt.Errorf("Failed to restore %s: %s", res.Server, err)
+	} else if _, err = c.WaitForRequest(res.RequestID, opts.PollInterval, opts.Timeout); err != nil {
+		return fmt.Errorf("Failed to restore %s: %s", res.Server, err)
+	}
+
+	if opts.PowerOn {
+		reqId, err := c.PowerOnServer(res.Server, acctAlias)
+		if err != nil {
+			return fmt.Errorf("Restored %s, but failed to power it on: %s", res.Server, err)
+		} else if _, err = c.WaitForRequest(reqId, opts.PollInterval, opts.Timeout); err != nil {
+			return fmt.Errorf("Restored %s, but failed to power it on: %s", res.Server, err)
+		}
+		res.PoweredOn = true
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+ * Fleet-wide rotation of server administrator/root passwords.
+ */
+package clcv1
+
+import (
+	"sync"
+	"time"
+	"fmt"
+)
+
+const (
+	// Number of attempts (and delay between them) to verify a changed password.
+	rotationVerifyAttempts = 5
+	rotationVerifyDelay    = 3 * time.Second
+)
+
+// Outcome of rotating the password of a single server.
+// Error messages never contain passwords.
+type RotationResult struct {
+	// The name of the server.
+	Server		string
+
+	// The administrator or root user name of the server.
+	Username	string
+
+	// The new password (empty if the change failed).
+	Password	string
+
+	// Whether GetServerCredentials confirmed the new password.
+	Verified	bool
+
+	// When the password was changed.
+	Changed		time.Time
+
+	// Error, if rotation failed.
+	Err		error
+}
+
+// Rotate the administrator/root passwords of the servers @names.
+// @acctAlias: The alias of the account that owns the servers (optional).
+// @parallel:  maximum number of servers to process concurrently (at least 1).
+// For each server, the current credentials are fetched, a new password that satisfies the
+// password policy of the server's operating system is generated and applied,
+// and the change is verified by reading back the credentials.
+// The results are returned in the order of @names.
+func (c *Client) RotatePasswords(names []string, acctAlias string, parallel int) (results []RotationResult) {
+	var wg sync.WaitGroup
+
+	if parallel < 1 {
+		parallel = 1
+	}
+	sem := make(chan struct{}, parallel)
+
+	results = make([]RotationResult, len(names))
+	for i, name := range names {
+		wg.Add(1)
+		go func(res *RotationResult, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			res.Server = name
+			res.Err    = c.rotatePassword(res, acctAlias)
+		}(&results[i], name)
+	}
+	wg.Wait()
+	return
+}
+
+// Rotate the password of @res.Server, filling in the remaining fields of @res.
+func (c *Client) rotatePassword(res *RotationResult, acctAlias string) error {
+	/* The operating system determines the password policy */
+	server, err := c.GetServer(res.Server, acctAlias)
+	if err != nil {
+		return fmt.Errorf("Failed to look up server %s: %s", res.Server, err)
+	}
+
+	creds, err := c.GetServerCredentials(res.Server, acctAlias)
+	if err != nil {
+		return fmt.Errorf("Failed to get current credentials of %s: %s", res.Server, err)
+	}
+	res.Username = creds.Username
+
+	newPass, err := PasswordPolicyFor(server.OperatingSystem).Generate(0)
+	if err != nil {
+		return err
+	}
+
+	if err = c.ServerChangePassword(res.Server, acctAlias, creds.Password, newPass); err != nil {
+		return fmt.Errorf("Failed to change password of %s: %s", res.Server, err)
+	}
+	res.Password, res.Changed = newPass, time.Now()
+
+	for i := 0; i < rotationVerifyAttempts; i++ {
+		if i > 0 {
+			time.Sleep(rotationVerifyDelay)
+		}
+		if creds, err = c.GetServerCredentials(res.Server, acctAlias); err == nil && creds.Password == newPass {
+			res.Verified = true
+			return nil
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed to verify new password of %s: %s", res.Server, err)
+	}
+	return fmt.Errorf("Password of %s was changed, but the new password could not be verified", res.Server)
+}
@@ -0,0 +1,201 @@
+/*
+ * Guarded deletion of servers and hardware groups.
+ */
+package clcv1
+
+import (
+	"io/ioutil"
+	"strings"
+	"time"
+	"fmt"
+)
+
+// Optional step to take before deleting a resource.
+type DeleteSafety int
+
+const (
+	// Delete right away.
+	SafetyNone DeleteSafety = iota
+
+	// Snapshot the server (or each server in the group) first.
+	SafetySnapshot
+
+	// Archive the server/group first.
+	SafetyArchive
+)
+
+func (s DeleteSafety) String() string {
+	switch s {
+	case SafetyNone:     return "none"
+	case SafetySnapshot: return "snapshot"
+	case SafetyArchive:  return "archive"
+	}
+	return fmt.Sprintf("Unknown safety step %d", int(s))
+}
+
+// Parse the string representation of a DeleteSafety value.
+func ParseDeleteSafety(s string) (DeleteSafety, error) {
+	for _, v := range []DeleteSafety{ SafetyNone, SafetySnapshot, SafetyArchive } {
+		if strings.EqualFold(s, v.String()) {
+			return v, nil
+		}
+	}
+	return SafetyNone, fmt.Errorf("Invalid safety step %q (expected none, snapshot or archive)", s)
+}
+
+// DeleteGuard holds the protection rules that apply to SafeDeleteServer and SafeDeleteHardwareGroup.
+type DeleteGuard struct {
+	// Name of an account custom field that marks servers as protected.
+	// A server is protected if this field has a value other than "" or "false" (optional).
+	ProtectField	string
+
+	// Server names, group names and group UUIDs that are protected locally.
+	Protected	map[string]bool
+
+	// Step to perform before deleting.
+	Safety		DeleteSafety
+
+	// How often to check on the safety step request (defaults to 5 seconds).
+	PollInterval	time.Duration
+
+	// How long to wait for the safety step request (defaults to DefaultRequestTimeout).
+	Timeout		time.Duration
+}
+
+// Add the entries of @path to the local protect-list of @g.
+// The file contains one server name, group name or group UUID per line; '#' starts a comment.
+func (g *DeleteGuard) LoadProtectList(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Failed to read protect-list: %s", err)
+	}
+
+	if g.Protected == nil {
+		g.Protected = make(map[string]bool)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			g.Protected[strings.ToUpper(line)] = true
+		}
+	}
+	return nil
+}
+
+// Return true if @name is on the local protect-list (case-insensitive).
+func (g *DeleteGuard) isListed(name string) bool {
+	return g.Protected[strings.ToUpper(name)]
+}
+
+// Return a non-nil error if server @s is protected.
+func (g *DeleteGuard) checkServer(s *Server) error {
+	if g.isListed(s.Name) {
+		return fmt.Errorf("Server %s is on the protect-list", s.Name)
+	}
+	if g.ProtectField != "" {
+		for _, f := range s.CustomFields {
+			if f.Name == g.ProtectField && f.Value != "" && !strings.EqualFold(f.Value, "false") {
+				return fmt.Errorf("Server %s is protected via custom field %q", s.Name, g.ProtectField)
+			}
+		}
+	}
+	return nil
+}
+
+// Delete server @name, subject to the protection rules and safety step of @g.
+// @confirm:   must repeat the server name (case-insensitive), to guard against accidental deletion.
+// @acctAlias: The alias of the account that owns the server (optional).
+// Returns the request ID of DeleteServer.
+func (c *Client) SafeDeleteServer(g *DeleteGuard, name, confirm, acctAlias string) (reqId int, err error) {
+	if !strings.EqualFold(name, confirm) {
+		return 0, fmt.Errorf("Deletion of %s not confirmed (confirmation %q does not match)", name, confirm)
+	}
+
+	server, err := c.GetServer(name, acctAlias)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to look up server %s: %s", name, err)
+	} else if err = g.checkServer(&server); err != nil {
+		return 0, err
+	}
+
+	switch g.Safety {
+	case SafetySnapshot:
+		err = c.safetyStep("snapshot " + name, func() (int, error) { return c.SnapshotServer(name, acctAlias) }, g)
+	case SafetyArchive:
+		err = c.safetyStep("archive " + name, func() (int, error) { return c.ArchiveServer(name, acctAlias) }, g)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return c.DeleteServer(name, acctAlias)
+}
+
+// Delete the Hardware Group @ref along with all child groups and servers, subject to @g.
+// @ref:       The group UUID, name or path (see LookupGroupNode).
+// @confirm:   must repeat either the group name or its UUID.
+// @location:  The data center location of the group (not needed if @ref is a path).
+// @acctAlias: The alias of the account that owns the group (optional).
+// The deletion is refused if the group, any of its sub-groups, or any contained server is protected.
+// Returns the request ID of DeleteHardwareGroup.
+func (c *Client) SafeDeleteHardwareGroup(g *DeleteGuard, ref, confirm, location, acctAlias string) (reqId int, err error) {
+	var servers []*Server
+
+	start, err := c.LookupGroupNode(ref, location, acctAlias, true)
+	if err != nil {
+		return 0, err
+	}
+	uuid := start.UUID
+
+	if start.IsSystemGroup {
+		return 0, fmt.Errorf("Refusing to delete system group %q", start.Name)
+	} else if confirm != uuid && !strings.EqualFold(confirm, start.Name) {
+		return 0, fmt.Errorf("Deletion of group %q not confirmed (confirmation %q does not match)", start.Name, confirm)
+	}
+
+	/* Check the entire subtree, since all of it will be deleted. */
+	if node := FindGroupNode(start, func(n *GroupNode) bool {
+		if g.isListed(n.UUID) || g.isListed(n.Name) {
+			err = fmt.Errorf("Group %q (%s) is on the protect-list", n.Name, n.UUID)
+			return true
+		}
+		for _, s := range n.Servers {
+			if err = g.checkServer(s); err != nil {
+				return true
+			}
+			servers = append(servers, s)
+		}
+		return false
+	}); node != nil {
+		return 0, fmt.Errorf("Not deleting group %q: %s", start.Name, err)
+	}
+
+	switch g.Safety {
+	case SafetySnapshot:
+		for _, s := range servers {
+			name := s.Name
+			if err = c.safetyStep("snapshot " + name, func() (int, error) { return c.SnapshotServer(name, acctAlias) }, g); err != nil {
+				return 0, err
+			}
+		}
+	case SafetyArchive:
+		err = c.safetyStep("archive group " + start.Name, func() (int, error) { return c.ArchiveHardwareGroup(uuid, acctAlias) }, g)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return c.DeleteHardwareGroup(uuid, acctAlias)
+}
+
+// Run the safety step @step (described by @what), and wait for its request to complete as configured in @g.
+func (c *Client) safetyStep(what string, step func() (int, error), g *DeleteGuard) error {
+	reqId, err := step()
+	if err != nil {
+		return fmt.Errorf("Safety step (%s) failed: %s", what, err)
+	}
+	if _, err = c.WaitForRequest(reqId, g.PollInterval, g.Timeout); err != nil {
+		return fmt.Errorf("Safety step (%s) did not complete: %s", what, err)
+	}
+	return nil
+}
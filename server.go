@@ -43,19 +43,19 @@ type Server struct {
 	IsHyperscale		bool
 
 	// Active, Archived, Deleted, UnderConstruction, QueuedForArchive, QueuedForDelete, or QueuedForRestore
-	Status			string
+	Status			ServerStatus
 
-	// The type of server. Standard or Premium
-	ServerType		int
+	// The type of server: Standard or Enterprise
+	ServerType		ServerType
 
-	// The service level/performance for the underlying data store.  Standard or Premium
-	ServiceLevel		int
+	// The service level/performance for the underlying data store: Premium or Standard
+	ServiceLevel		ServiceLevel
 
 	// Operating System of the server (see below).
 	OperatingSystem		OperatingSystem
 
-	// StringThe current power state of the Server (Stopped, Started, Paused).
-	PowerState		string
+	// The current power state of the Server (Stopped, Started, Paused).
+	PowerState		PowerState
 
 	// Indicates if the Server is in Maintenance Mode.
 	InMaintenanceMode	bool
@@ -120,6 +120,18 @@ type CustomField struct {
 	CustomFieldID	int
 }
 
+// Custom Field setting, as used by CreateServerReq and ConfigureServerReq.
+type CustomFieldValue struct {
+	// Unique identifier that is associated with the Account Custom Field.
+	// Call Account/GetCustomFields for a list of all custom fields set at the account level.
+	ID		string
+
+	// For Text: Any value;
+	// For Option values, call Account/GetCustomFields to see possible values to pass in.
+	// Checkbox values should be "true" or "false".
+	Value		string
+}
+
 /*
  * Server Lists
  */
@@ -462,12 +474,12 @@ type CreateServerReq struct {
 	//The type of server to create (required)
 	// 1 = Standard
 	// 2 = Enterprise
-	ServerType		int
+	ServerType		ServerType
 
 	// The service level/performance for the underlying data store (required)
 	// 1 = Premium
 	// 2 = Standard
-	ServiceLevel		int
+	ServiceLevel		ServiceLevel
 
 	// The number of processors to configure the server with (required)
 	Cpu			int
@@ -497,14 +509,9 @@ type CreateServerReq struct {
 	// Leave blank to have the system generate a password
 	Password		string
 
-	// A list of Custom Fields associated to this server
-	// ID:    Unique identifier that is associated with the Account Custom Field.
-	//        Call Account/GetCustomFields for a list of all custom fields set at the account level.
-	// Value:
-	//        - For Text: Any value;
-	//        - For Option values, call Account/GetCustomFields to see possible values to pass in.
-	//        - Checkbox values should be "true" or "false".
-	CustomFields            []struct { ID, Value string }
+	// A list of Custom Fields associated to this server (see CustomFieldValue).
+	// Use CustomFieldResolver to set these by name.
+	CustomFields            []CustomFieldValue
 }
 
 // Create a new Server
@@ -541,7 +548,7 @@ type ConfigureServerReq struct {
 
 	// A list of Custom Fields associated to this server.
 	// See field of identical name in above CreateServerReq
-	CustomFields            []struct { ID, Value string }
+	CustomFields            []CustomFieldValue
 }
 
 // Configure the CPU, Memory, Group and additional storage for a Server.
@@ -0,0 +1,312 @@
+/*
+ * Server change feed: poll GetAllServersByModifiedDates and emit field-level differences.
+ */
+package clcv1
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"time"
+	"fmt"
+	"os"
+)
+
+const (
+	// Date format accepted by the BeginDate/EndDate arguments of GetAllServersByModifiedDates.
+	modifiedDateFormat = "2006-01-02T15:04:05"
+
+	// Overlap between successive polling windows, to allow for clock skew between
+	// the client and the CLC API. Repeated sightings of a server do not cause duplicate
+	// changes, since each server is compared against its last-seen state.
+	changeFeedOverlap = 5 * time.Minute
+
+	// The API documentation does not say in which time zone BeginDate is interpreted (the
+	// timestamps it returns are UTC epoch milliseconds, but the query arguments have no zone).
+	// The window start is sent as UTC, and moved back by the largest UTC offset, so that no
+	// modification is missed whichever zone the API assumes.
+	changeFeedZoneSlack = 14 * time.Hour
+
+	// Default number of polls between full listings of all servers (see FullSyncInterval).
+	defaultFullSyncInterval = 10
+)
+
+type ServerChangeType int
+
+const (
+	ServerCreated  ServerChangeType = iota + 1
+	ServerModified
+	ServerDeleted
+)
+
+func (t ServerChangeType) String() string {
+	switch t {
+	case ServerCreated:  return "Created"
+	case ServerModified: return "Modified"
+	case ServerDeleted:  return "Deleted"
+	}
+	return fmt.Sprintf("Unknown change type %d", int(t))
+}
+
+// A single field that differs between two sightings of a server.
+type FieldChange struct {
+	// Name of the Server field, e.g. "Cpu" or "PowerState".
+	Field	string
+
+	// Previous and current value of @Field, as printed by fmt.Sprint.
+	Old	string
+	New	string
+}
+
+func (f FieldChange) String() string {
+	return fmt.Sprintf("%s %s→%s", f.Field, f.Old, f.New)
+}
+
+// A change to a server detected by the change feed.
+type ServerChange struct {
+	// What happened to the server.
+	Type		ServerChangeType
+
+	// The name of the server.
+	Name		string
+
+	// When the change happened (DateModified of the server, or time of detection for deletions).
+	When		time.Time
+
+	// Who made the change (ModifiedBy of the server; empty for deletions).
+	ModifiedBy	string
+
+	// Field-level differences (ServerModified only).
+	Fields		[]FieldChange
+
+	// The current state of the server (ServerCreated/ServerModified),
+	// or the last-seen state (ServerDeleted).
+	Server		Server
+}
+
+func (c ServerChange) String() string {
+	var s = fmt.Sprintf("%s %s", c.Name, c.Type)
+
+	for i, f := range c.Fields {
+		if i == 0 {
+			s += ":"
+		}
+		s += " " + f.String()
+	}
+	return s
+}
+
+// Server fields that are not compared, since they change with every modification or are deprecated.
+var serverDiffIgnore = map[string]bool{
+	"DateModified":    true,
+	"ID":              true,
+	"HardwareGroupID": true,
+}
+
+// Compute the field-level differences between @from and @to.
+func DiffServers(from, to *Server) (changes []FieldChange) {
+	var a, b = reflect.ValueOf(*from), reflect.ValueOf(*to)
+
+	for i := 0; i < a.NumField(); i++ {
+		name := a.Type().Field(i).Name
+		if serverDiffIgnore[name] {
+			continue
+		}
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			changes = append(changes, FieldChange{
+				Field: name,
+				Old:   fmt.Sprint(a.Field(i).Interface()),
+				New:   fmt.Sprint(b.Field(i).Interface()),
+			})
+		}
+	}
+	return
+}
+
+// Persisted state of the change feed.
+type changeFeedState struct {
+	// End of the last polling window.
+	Checkpoint	time.Time
+
+	// Last-seen state of each server, indexed by name.
+	Servers		map[string]Server
+
+	// Number of polls since the last full listing (see FullSyncInterval).
+	PollsSinceSync	int
+}
+
+// ServerChangeFeed remembers the last-seen state of each server and emits changes on each Poll.
+type ServerChangeFeed struct {
+	client		*Client
+
+	// Arguments passed to GetAllServers/GetAllServersByModifiedDates (all optional).
+	acctAlias	string
+	hwGrpUUID	string
+	location	string
+
+	// File to persist the checkpoint in (may be empty to keep state in memory only).
+	stateFile	string
+
+	// Every @FullSyncInterval polls, all servers are listed via GetAllServers, to detect deleted
+	// servers (which GetAllServersByModifiedDates does not return). Defaults to 10; a value
+	// of 1 or less lists all servers on every poll. The count is persisted along with the checkpoint.
+	FullSyncInterval int
+
+	state		changeFeedState
+}
+
+// Create a new server change feed.
+// @acctAlias, @hwGrpUUID, @location: see GetAllServers.
+// @stateFile: file to load/save the checkpoint from/to; if empty, no state is persisted.
+// If @stateFile exists, polling resumes from the saved checkpoint, so that changes made
+// while the feed was not running are still reported.
+func (c *Client) NewServerChangeFeed(acctAlias, hwGrpUUID, location, stateFile string) (*ServerChangeFeed, error) {
+	f := &ServerChangeFeed{
+		client:    c,
+		acctAlias: acctAlias,
+		hwGrpUUID: hwGrpUUID,
+		location:  location,
+		stateFile: stateFile,
+
+		FullSyncInterval: defaultFullSyncInterval,
+	}
+
+	if stateFile != "" {
+		if data, err := ioutil.ReadFile(stateFile); err == nil {
+			if err = json.Unmarshal(data, &f.state); err != nil {
+				return nil, fmt.Errorf("Failed to decode change feed state in %s: %s", stateFile, err)
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("Failed to read change feed state: %s", err)
+		}
+	}
+	return f, nil
+}
+
+// Return the end of the last polling window (zero if the feed has not been polled yet).
+func (f *ServerChangeFeed) Checkpoint() time.Time {
+	return f.state.Checkpoint
+}
+
+// Poll for changes since the last checkpoint.
+// Changed servers are found via GetAllServersByModifiedDates; deleted servers are only detected
+// on the periodic full listing (see FullSyncInterval).
+// The first poll (without saved state) records the current state of all servers as baseline
+// and does not emit any changes.
+// Changes are returned sorted by time, then by server name.
+func (f *ServerChangeFeed) Poll() (changes []ServerChange, err error) {
+	var now = time.Now().UTC()
+
+	if f.state.Servers == nil {
+		current, err := f.client.GetAllServers(f.acctAlias, f.hwGrpUUID, f.location)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list servers: %s", err)
+		}
+		f.state.Servers = make(map[string]Server)
+		for _, s := range current {
+			f.state.Servers[s.Name] = s
+		}
+	} else {
+		/* The end date is left empty, which the API takes as the current time. */
+		begin := f.state.Checkpoint.Add(-changeFeedOverlap - changeFeedZoneSlack).Format(modifiedDateFormat)
+		modified, err := f.client.GetAllServersByModifiedDates(f.acctAlias, f.hwGrpUUID, f.location, begin, "")
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list servers modified since %s: %s", begin, err)
+		}
+
+		for _, s := range modified {
+			if change := f.update(s); change != nil {
+				changes = append(changes, *change)
+			}
+		}
+
+		if f.state.PollsSinceSync++; f.state.PollsSinceSync >= f.FullSyncInterval {
+			deleted, err := f.fullSync(now)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, deleted...)
+			f.state.PollsSinceSync = 0
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if !changes[i].When.Equal(changes[j].When) {
+			return changes[i].When.Before(changes[j].When)
+		}
+		return changes[i].Name < changes[j].Name
+	})
+
+	f.state.Checkpoint = now
+	return changes, f.save()
+}
+
+// List all servers, returning servers that have disappeared since they were last seen as deleted
+// (at time @now), along with any changes that the modified-dates query did not report.
+func (f *ServerChangeFeed) fullSync(now time.Time) (changes []ServerChange, err error) {
+	var seen = make(map[string]bool)
+
+	current, err := f.client.GetAllServers(f.acctAlias, f.hwGrpUUID, f.location)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list servers: %s", err)
+	}
+
+	for _, s := range current {
+		seen[s.Name] = true
+		if change := f.update(s); change != nil {
+			changes = append(changes, *change)
+		}
+	}
+
+	for name, s := range f.state.Servers {
+		if !seen[name] {
+			changes = append(changes, ServerChange{
+				Type:   ServerDeleted,
+				Name:   name,
+				When:   now,
+				Server: s,
+			})
+			delete(f.state.Servers, name)
+		}
+	}
+	return changes, nil
+}
+
+// Record the current state of @s, returning the change (if any) relative to the last-seen state.
+func (f *ServerChangeFeed) update(s Server) *ServerChange {
+	var change = &ServerChange{
+		Name:       s.Name,
+		When:       s.DateModified.Time,
+		ModifiedBy: s.ModifiedBy,
+		Server:     s,
+	}
+
+	if prev, ok := f.state.Servers[s.Name]; !ok {
+		change.Type = ServerCreated
+	} else if change.Fields = DiffServers(&prev, &s); len(change.Fields) > 0 {
+		change.Type = ServerModified
+	} else {
+		return nil
+	}
+	f.state.Servers[s.Name] = s
+	return change
+}
+
+// Persist the state of @f, if a state file was configured.
+func (f *ServerChangeFeed) save() error {
+	if f.stateFile == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(&f.state)
+	if err != nil {
+		return fmt.Errorf("Failed to encode change feed state: %s", err)
+	}
+
+	/* Write to a temporary file first, so that an interrupted write does not lose the checkpoint */
+	if err = ioutil.WriteFile(f.stateFile + ".tmp", data, 0600); err != nil {
+		return fmt.Errorf("Failed to save change feed state: %s", err)
+	}
+	return os.Rename(f.stateFile + ".tmp", f.stateFile)
+}
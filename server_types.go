@@ -0,0 +1,182 @@
+/*
+ * Enumerated server attributes: server type, storage service level, status and power state.
+ */
+package clcv1
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"fmt"
+)
+
+// The type of a server.
+type ServerType int
+
+const (
+	StandardServer   ServerType = 1
+	EnterpriseServer ServerType = 2
+)
+
+func (t ServerType) String() string {
+	switch t {
+	case StandardServer:   return "Standard"
+	case EnterpriseServer: return "Enterprise"
+	}
+	return fmt.Sprintf("Unknown server type %d", int(t))
+}
+
+// Return true if @t is one of the defined server types.
+func (t ServerType) Valid() bool {
+	return t == StandardServer || t == EnterpriseServer
+}
+
+// Parse @s as server type, either by name (case-insensitive) or numeric value.
+func ParseServerType(s string) (ServerType, error) {
+	for _, t := range []ServerType{ StandardServer, EnterpriseServer } {
+		if strings.EqualFold(s, t.String()) || s == strconv.Itoa(int(t)) {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("Invalid server type %q (expected 1 = Standard or 2 = Enterprise)", s)
+}
+
+// The API expects and returns the numeric value.
+func (t ServerType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int(t))
+}
+
+// Accept both the numeric value (as returned by the API) and the name.
+func (t *ServerType) UnmarshalJSON(b []byte) (err error) {
+	var name string
+
+	if err = json.Unmarshal(b, (*int)(t)); err == nil {
+		return nil
+	} else if err = json.Unmarshal(b, &name); err != nil {
+		return fmt.Errorf("Invalid server type value %s", string(b))
+	}
+	*t, err = ParseServerType(name)
+	return
+}
+
+// The service level/performance of the underlying data store.
+// Note that the numbering is reversed with respect to ServerType: 1 is Premium.
+type ServiceLevel int
+
+const (
+	PremiumStorage  ServiceLevel = 1
+	StandardStorage ServiceLevel = 2
+)
+
+func (l ServiceLevel) String() string {
+	switch l {
+	case PremiumStorage:  return "Premium"
+	case StandardStorage: return "Standard"
+	}
+	return fmt.Sprintf("Unknown service level %d", int(l))
+}
+
+// Return true if @l is one of the defined service levels.
+func (l ServiceLevel) Valid() bool {
+	return l == PremiumStorage || l == StandardStorage
+}
+
+// Parse @s as service level, either by name (case-insensitive) or numeric value.
+func ParseServiceLevel(s string) (ServiceLevel, error) {
+	for _, l := range []ServiceLevel{ PremiumStorage, StandardStorage } {
+		if strings.EqualFold(s, l.String()) || s == strconv.Itoa(int(l)) {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("Invalid service level %q (expected 1 = Premium or 2 = Standard)", s)
+}
+
+// The API expects and returns the numeric value.
+func (l ServiceLevel) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int(l))
+}
+
+// Accept both the numeric value (as returned by the API) and the name.
+func (l *ServiceLevel) UnmarshalJSON(b []byte) (err error) {
+	var name string
+
+	if err = json.Unmarshal(b, (*int)(l)); err == nil {
+		return nil
+	} else if err = json.Unmarshal(b, &name); err != nil {
+		return fmt.Errorf("Invalid service level value %s", string(b))
+	}
+	*l, err = ParseServiceLevel(name)
+	return
+}
+
+// The status of a server, as reported in Server.Status.
+// Values not listed below are passed through unchanged, but are not Valid.
+type ServerStatus string
+
+const (
+	StatusActive            ServerStatus = "Active"
+	StatusArchived          ServerStatus = "Archived"
+	StatusDeleted           ServerStatus = "Deleted"
+	StatusUnderConstruction ServerStatus = "UnderConstruction"
+	StatusQueuedForArchive  ServerStatus = "QueuedForArchive"
+	StatusQueuedForDelete   ServerStatus = "QueuedForDelete"
+	StatusQueuedForRestore  ServerStatus = "QueuedForRestore"
+)
+
+var serverStatuses = []ServerStatus{
+	StatusActive, StatusArchived, StatusDeleted, StatusUnderConstruction,
+	StatusQueuedForArchive, StatusQueuedForDelete, StatusQueuedForRestore,
+}
+
+func (s ServerStatus) String() string {
+	return string(s)
+}
+
+// Return true if @s is one of the documented server statuses.
+func (s ServerStatus) Valid() bool {
+	for _, v := range serverStatuses {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse @s as server status (case-insensitive).
+func ParseServerStatus(s string) (ServerStatus, error) {
+	for _, v := range serverStatuses {
+		if strings.EqualFold(s, string(v)) {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("Invalid server status %q", s)
+}
+
+// The power state of a server, as reported in Server.PowerState.
+// Values not listed below are passed through unchanged, but are not Valid.
+type PowerState string
+
+const (
+	PowerStarted PowerState = "Started"
+	PowerStopped PowerState = "Stopped"
+	PowerPaused  PowerState = "Paused"
+)
+
+func (p PowerState) String() string {
+	return string(p)
+}
+
+// Return true if @p is one of the documented power states.
+func (p PowerState) Valid() bool {
+	return p == PowerStarted || p == PowerStopped || p == PowerPaused
+}
+
+// Parse @s as power state (case-insensitive).
+func ParsePowerState(s string) (PowerState, error) {
+	for _, p := range []PowerState{ PowerStarted, PowerStopped, PowerPaused } {
+		if strings.EqualFold(s, string(p)) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("Invalid power state %q (expected Started, Stopped or Paused)", s)
+}
@@ -0,0 +1,268 @@
+/*
+ * Snapshot lifecycle policies on top of GetSnapshots, SnapshotServer and DeleteSnapshot.
+ */
+package clcv1
+
+import (
+	"strings"
+	"time"
+	"fmt"
+)
+
+// A snapshot policy applies to a set of servers, given by group and/or server names.
+// CLC keeps at most one snapshot per server, hence taking a new snapshot replaces the existing one.
+type SnapshotPolicy struct {
+	// Descriptive name of the policy (used in reports only).
+	Name			string
+
+	// The data center location of the servers (optional, defaults to the account's primary data center).
+	Location		string
+
+	// Apply the policy to all servers in this Hardware Group and its sub-groups (optional).
+	HardwareGroupUUID	string
+
+	// Apply the policy to these servers (optional).
+	Servers			[]string
+
+	// Take a new snapshot if a server has none, or if the existing one is at least this many hours old.
+	// For a nightly job, use a value slightly below 24 to allow for variations in run time.
+	// Set to 0 to disable taking snapshots.
+	SnapshotEveryHours	int
+
+	// Delete snapshots that are at least this many days old. Set to 0 to disable.
+	MaxAgeDays		int
+}
+
+type SnapshotActionType int
+
+const (
+	SnapshotCreate SnapshotActionType = iota + 1
+	SnapshotDelete
+)
+
+func (t SnapshotActionType) String() string {
+	switch t {
+	case SnapshotCreate: return "create"
+	case SnapshotDelete: return "delete"
+	}
+	return fmt.Sprintf("Unknown snapshot action %d", int(t))
+}
+
+// A single step of applying a SnapshotPolicy.
+type SnapshotAction struct {
+	// The name of the policy this action results from.
+	Policy		string
+
+	// The server the action applies to.
+	Server		string
+
+	// Whether to create or delete a snapshot.
+	Type		SnapshotActionType
+
+	// The existing snapshot (SnapshotDelete, or SnapshotCreate replacing an existing snapshot).
+	Snapshot	string
+
+	// When the existing snapshot was created (zero if there is none).
+	DateCreated	time.Time
+
+	// Human-readable reason for this action.
+	Reason		string
+
+	// Request ID of SnapshotServer (SnapshotCreate, after applying).
+	RequestID	int
+
+	// Error, if applying the action failed.
+	Err		error
+}
+
+// Age of the existing snapshot referred to by @a (0 if there is none).
+func (a *SnapshotAction) Age() time.Duration {
+	if a.DateCreated.IsZero() {
+		return 0
+	}
+	return time.Since(a.DateCreated)
+}
+
+// Determine the servers that @p applies to.
+func (c *Client) snapshotPolicyServers(p *SnapshotPolicy, acctAlias string) (names []string, err error) {
+	var seen = make(map[string]bool)
+
+	if p.HardwareGroupUUID != "" {
+		servers, err := c.GetAllServers(acctAlias, p.HardwareGroupUUID, p.Location)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list servers of group %s: %s", p.HardwareGroupUUID, err)
+		}
+		for _, s := range servers {
+			/* Templates can not be snapshotted */
+			if !s.IsTemplate && !seen[s.Name] {
+				seen[s.Name] = true
+				names = append(names, s.Name)
+			}
+		}
+	}
+
+	for _, name := range p.Servers {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return
+}
+
+// Compute the actions needed to enforce @policies, without changing anything (dry run).
+// @acctAlias: The alias of the account that owns the servers (optional).
+// A server that is covered by several policies is evaluated once, against the combination of
+// these policies (see mergeSnapshotPolicies), so that it gets at most one snapshot creation and
+// each snapshot is deleted at most once. Actions are listed by server, in order of first coverage.
+func (c *Client) PlanSnapshotPolicies(policies []SnapshotPolicy, acctAlias string) (actions []SnapshotAction, err error) {
+	var servers  []string
+	var covering = make(map[string][]*SnapshotPolicy)
+
+	for i := range policies {
+		var p = &policies[i]
+
+		if p.SnapshotEveryHours < 0 || p.MaxAgeDays < 0 {
+			return nil, fmt.Errorf("Policy %q: negative interval/age values are not allowed", p.Name)
+		}
+
+		names, err := c.snapshotPolicyServers(p, acctAlias)
+		if err != nil {
+			return nil, fmt.Errorf("Policy %q: %s", p.Name, err)
+		}
+
+		for _, name := range names {
+			name = strings.ToUpper(name)
+			if covering[name] == nil {
+				servers = append(servers, name)
+			}
+			covering[name] = append(covering[name], p)
+		}
+	}
+
+	for _, name := range servers {
+		p := mergeSnapshotPolicies(covering[name])
+
+		snaps, err := c.GetSnapshots(name, acctAlias)
+		if err != nil {
+			return nil, fmt.Errorf("Policy %q: failed to list snapshots of %s: %s", p.Name, name, err)
+		}
+		actions = append(actions, planSnapshotActions(p, name, snaps)...)
+	}
+	return
+}
+
+// Combine the policies @ps that cover the same server. Where they conflict, the stricter setting wins:
+// the shortest SnapshotEveryHours (most frequent snapshots) and the shortest MaxAgeDays (shortest
+// retention), each ignoring policies that disable it (0). The name of the result lists all policies.
+func mergeSnapshotPolicies(ps []*SnapshotPolicy) *SnapshotPolicy {
+	var m     = &SnapshotPolicy{}
+	var names []string
+
+	if len(ps) == 1 {
+		return ps[0]
+	}
+
+	minPositive := func(a, b int) int {
+		if a == 0 || (b > 0 && b < a) {
+			return b
+		}
+		return a
+	}
+
+	for _, p := range ps {
+		names = append(names, p.Name)
+		m.SnapshotEveryHours = minPositive(m.SnapshotEveryHours, p.SnapshotEveryHours)
+		m.MaxAgeDays         = minPositive(m.MaxAgeDays, p.MaxAgeDays)
+	}
+	m.Name = strings.Join(names, "+")
+	return m
+}
+
+// Evaluate policy @p against the existing snapshots @snaps of server @name.
+func planSnapshotActions(p *SnapshotPolicy, name string, snaps []SnapshotAttribute) (actions []SnapshotAction) {
+	var maxAge    = time.Duration(p.MaxAgeDays) * 24 * time.Hour
+	var snapEvery = time.Duration(p.SnapshotEveryHours) * time.Hour
+	var needSnap  = p.SnapshotEveryHours > 0
+
+	for _, s := range snaps {
+		age := time.Since(s.DateCreated.Time)
+
+		if needSnap && (age >= snapEvery || (p.MaxAgeDays > 0 && age >= maxAge)) {
+			/* Replacing requires removing the old snapshot first; creation is listed below. */
+			var reason = fmt.Sprintf("replaced by new snapshot (older than %d hours)", p.SnapshotEveryHours)
+
+			if age < snapEvery {
+				reason = fmt.Sprintf("replaced by new snapshot (older than %d days)", p.MaxAgeDays)
+			}
+			actions = append(actions, SnapshotAction{
+				Policy:      p.Name,
+				Server:      name,
+				Type:        SnapshotDelete,
+				Snapshot:    s.Name,
+				DateCreated: s.DateCreated.Time,
+				Reason:      reason,
+			})
+		} else if needSnap {
+			/* Recent enough - keep it */
+			needSnap = false
+		} else if p.MaxAgeDays > 0 && age >= maxAge {
+			actions = append(actions, SnapshotAction{
+				Policy:      p.Name,
+				Server:      name,
+				Type:        SnapshotDelete,
+				Snapshot:    s.Name,
+				DateCreated: s.DateCreated.Time,
+				Reason:      fmt.Sprintf("older than %d days", p.MaxAgeDays),
+			})
+		}
+	}
+
+	if needSnap {
+		var reason = "no snapshot"
+
+		if len(snaps) > 0 {
+			reason = "existing snapshot is too old"
+		}
+		actions = append(actions, SnapshotAction{
+			Policy: p.Name,
+			Server: name,
+			Type:   SnapshotCreate,
+			Reason: reason,
+		})
+	}
+	return
+}
+
+// Carry out @actions (as returned by PlanSnapshotPolicies), in order.
+// @acctAlias: The alias of the account that owns the servers (optional).
+// The outcome of each action is recorded in its RequestID/Err fields. If deleting the snapshot
+// of a server fails, the subsequent snapshot creation for that server is skipped.
+// Returns the number of failed actions.
+func (c *Client) ApplySnapshotActions(actions []SnapshotAction, acctAlias string) (failed int) {
+	var deleteFailed = make(map[string]bool)
+
+	for i := range actions {
+		var a = &actions[i]
+
+		switch a.Type {
+		case SnapshotDelete:
+			if a.Err = c.DeleteSnapshot(a.Snapshot, a.Server, acctAlias); a.Err != nil {
+				deleteFailed[a.Server] = true
+			}
+		case SnapshotCreate:
+			if deleteFailed[a.Server] {
+				a.Err = fmt.Errorf("Not taking snapshot, since deleting the existing one failed")
+			} else {
+				a.RequestID, a.Err = c.SnapshotServer(a.Server, acctAlias)
+			}
+		default:
+			a.Err = fmt.Errorf("Unsupported snapshot action %s", a.Type)
+		}
+
+		if a.Err != nil {
+			failed++
+		}
+	}
+	return
+}
@@ -0,0 +1,177 @@
+/*
+ * Use account custom fields as tags: bulk-set them on servers, and query servers by them.
+ */
+package clcv1
+
+import (
+	"strings"
+	"sync"
+	"sort"
+	"fmt"
+)
+
+// Outcome of tagging a single server.
+type TagResult struct {
+	// The name of the server.
+	Server		string
+
+	// The custom field changes made (empty if the server was already tagged as requested).
+	Changes		[]FieldChange
+
+	// Request ID of ConfigureServer (0 if no change was needed, or on error).
+	RequestID	int
+
+	// Error, if tagging failed.
+	Err		error
+}
+
+// Set the custom fields @tags (name -> value) on each of the servers @names.
+// @r:         resolver for the account custom fields.
+// @acctAlias: The alias of the account that owns the servers (optional).
+// @parallel:  maximum number of servers to process concurrently (at least 1).
+// Each server's current configuration (group, CPU, memory, other custom fields) is preserved;
+// servers that already carry the requested values are not reconfigured.
+// Returns an error without changing anything if @tags are not valid; otherwise
+// the per-server results are returned in the order of @names.
+func (c *Client) TagServers(r *CustomFieldResolver, names []string, tags map[string]string,
+			    acctAlias string, parallel int) (results []TagResult, err error) {
+	var wg sync.WaitGroup
+
+	values, err := r.Values(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if parallel < 1 {
+		parallel = 1
+	}
+	sem := make(chan struct{}, parallel)
+
+	results = make([]TagResult, len(names))
+	for i, name := range names {
+		wg.Add(1)
+		go func(res *TagResult, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			res.Server = name
+			res.Changes, res.RequestID, res.Err = c.tagServer(r, name, values, acctAlias)
+		}(&results[i], name)
+	}
+	wg.Wait()
+	return
+}
+
+// Apply the custom field @values to server @name.
+func (c *Client) tagServer(r *CustomFieldResolver, name string, values []CustomFieldValue,
+			   acctAlias string) (changes []FieldChange, reqId int, err error) {
+	server, err := c.GetServer(name, acctAlias)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Failed to look up server %s: %s", name, err)
+	}
+
+	fields := ServerCustomFieldValues(server.CustomFields)
+	for _, v := range values {
+		var found bool
+
+		for i := range fields {
+			if fields[i].ID == v.ID {
+				if found = true; fields[i].Value != v.Value {
+					changes = append(changes, r.fieldChange(v.ID, fields[i].Value, v.Value))
+					fields[i].Value = v.Value
+				}
+				break
+			}
+		}
+		if !found {
+			changes = append(changes, r.fieldChange(v.ID, "", v.Value))
+			fields = append(fields, v)
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil, 0, nil
+	}
+
+	reqId, err = c.ConfigureServer(&ConfigureServerReq{
+		Name:              server.Name,
+		HardwareGroupUUID: server.HardwareGroupUUID,
+		AccountAlias:      acctAlias,
+		Cpu:               server.Cpu,
+		MemoryGB:          server.MemoryGB,
+		CustomFields:      fields,
+	})
+	return
+}
+
+// Describe the change of custom field @id from @from to @to.
+func (r *CustomFieldResolver) fieldChange(id, from, to string) FieldChange {
+	var name = id
+
+	if d, ok := r.byID[id]; ok {
+		name = d.Name
+	}
+	return FieldChange{ Field: name, Old: from, New: to }
+}
+
+// Return the servers in @servers whose custom fields match all of @tags (name -> value).
+// Values are converted as in CustomFieldResolver.Value (e.g. option names to their stored values), and
+// compared case-insensitively; an empty value matches servers that do not have the field set.
+func (r *CustomFieldResolver) FilterServers(servers []Server, tags map[string]string) (res []Server, err error) {
+	var want = make(map[string]string)
+
+	for name, value := range tags {
+		d, err := r.Lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		if value != "" {
+			cfv, err := r.Value(name, value)
+			if err != nil {
+				return nil, err
+			}
+			value = cfv.Value
+		}
+		want[d.Name] = value
+	}
+
+	for _, s := range servers {
+		var have = r.ToMap(s.CustomFields)
+		var match = true
+
+		for name, value := range want {
+			if !strings.EqualFold(have[name], value) {
+				match = false
+				break
+			}
+		}
+		if match {
+			res = append(res, s)
+		}
+	}
+	return
+}
+
+// Report the servers in @servers that lack a value for one or more required custom fields.
+// Returns a map of server name -> sorted names of the missing fields.
+func (r *CustomFieldResolver) MissingRequired(servers []Server) map[string][]string {
+	var missing = make(map[string][]string)
+
+	for _, s := range servers {
+		if s.IsTemplate {
+			continue
+		}
+		have := r.ToMap(s.CustomFields)
+		for _, d := range r.Definitions() {
+			if d.IsRequired && have[d.Name] == "" {
+				missing[s.Name] = append(missing[s.Name], d.Name)
+			}
+		}
+	}
+
+	for name := range missing {
+		sort.Strings(missing[name])
+	}
+	return missing
+}
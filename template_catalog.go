@@ -0,0 +1,244 @@
+/*
+ * Catalog of server templates across all data centers.
+ */
+package clcv1
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"sort"
+	"fmt"
+)
+
+// A template, together with the locations it is available at.
+type CatalogEntry struct {
+	// The name of the template.
+	Name		string
+
+	// The description of the template (from the first location it was found at).
+	Description	string
+
+	// The operating system of the template.
+	OperatingSystem	OperatingSystem
+
+	// Structured description of @OperatingSystem.
+	Info		OSInfo
+
+	// Sorted aliases of the locations the template is available at.
+	Locations	[]string
+
+	// Sorted aliases of the catalog locations the template is not available at.
+	MissingFrom	[]string
+}
+
+// Return true if @e is available at @location.
+func (e *CatalogEntry) AvailableAt(location string) bool {
+	for _, l := range e.Locations {
+		if strings.EqualFold(l, location) {
+			return true
+		}
+	}
+	return false
+}
+
+// TemplateCatalog indexes the templates of several locations.
+type TemplateCatalog struct {
+	// Sorted aliases of all locations in the catalog.
+	Locations	[]string
+
+	// Catalog entries, sorted by name.
+	Entries		[]*CatalogEntry
+}
+
+// Build a catalog of the templates available at all locations returned by GetLocations.
+// @acctAlias: The alias of the account (optional).
+// @parallel:  maximum number of locations to query concurrently (at least 1).
+// Locations that could not be queried are reported via @err, and left out of the catalog.
+func (c *Client) LoadTemplateCatalog(acctAlias string, parallel int) (cat *TemplateCatalog, err error) {
+	var mu    sync.Mutex
+	var wg    sync.WaitGroup
+	var errs  []string
+	var byLoc = make(map[string][]ServerTemplate)
+
+	locations, err := c.GetLocations()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list locations: %s", err)
+	}
+
+	if parallel < 1 {
+		parallel = 1
+	}
+	sem := make(chan struct{}, parallel)
+
+	for _, l := range locations {
+		wg.Add(1)
+		go func(location string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			templates, err := c.ListAvailableServerTemplates(acctAlias, location)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", location, err))
+			} else {
+				byLoc[location] = templates
+			}
+		}(l.Alias)
+	}
+	wg.Wait()
+
+	cat = NewTemplateCatalog(byLoc)
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		err = fmt.Errorf("Failed to list templates at %d location(s): %s", len(errs), strings.Join(errs, "; "))
+	}
+	return
+}
+
+// Build a catalog from @byLocation, which maps location alias -> templates at that location.
+func NewTemplateCatalog(byLocation map[string][]ServerTemplate) *TemplateCatalog {
+	var cat    = &TemplateCatalog{}
+	var byName = make(map[string]*CatalogEntry)
+
+	for loc, templates := range byLocation {
+		cat.Locations = append(cat.Locations, loc)
+		for _, t := range templates {
+			e, ok := byName[t.Name]
+			if !ok {
+				e = &CatalogEntry{
+					Name:            t.Name,
+					Description:     t.Description,
+					OperatingSystem: t.OperatingSystem,
+				}
+				e.Info, _ = t.OperatingSystem.Info()
+				byName[t.Name] = e
+				cat.Entries = append(cat.Entries, e)
+			}
+			e.Locations = append(e.Locations, loc)
+		}
+	}
+	sort.Strings(cat.Locations)
+	sort.Slice(cat.Entries, func(i, j int) bool { return cat.Entries[i].Name < cat.Entries[j].Name })
+
+	for _, e := range cat.Entries {
+		sort.Strings(e.Locations)
+		for _, loc := range cat.Locations {
+			if !e.AvailableAt(loc) {
+				e.MissingFrom = append(e.MissingFrom, loc)
+			}
+		}
+	}
+	return cat
+}
+
+// Criteria for searching the template catalog. Empty/zero fields match any template.
+type TemplateQuery struct {
+	// Operating system family (e.g. "Linux", "Windows") or distribution (e.g. "Ubuntu"), case-insensitive.
+	OS		string
+
+	// Major version, e.g. "14" or "2012 R2".
+	Version		string
+
+	// Word size of the architecture: 32 or 64.
+	Bits		int
+
+	// Windows edition, e.g. "Datacenter".
+	Edition		string
+
+	// The template must be available at all of these locations.
+	Locations	[]string
+
+	// Only return the matching template(s) with the highest OS version.
+	Latest		bool
+}
+
+// Return true if @e satisfies @q.
+func (q *TemplateQuery) matches(e *CatalogEntry) bool {
+	if q.OS != "" && !strings.EqualFold(q.OS, e.Info.Family.String()) && !strings.EqualFold(q.OS, e.Info.Distribution) {
+		return false
+	} else if q.Version != "" && !strings.EqualFold(q.Version, e.Info.Version) {
+		return false
+	} else if q.Bits != 0 && q.Bits != e.Info.Bits {
+		return false
+	} else if q.Edition != "" && !strings.EqualFold(q.Edition, e.Info.Edition) {
+		return false
+	}
+
+	for _, loc := range q.Locations {
+		if !e.AvailableAt(loc) {
+			return false
+		}
+	}
+	return true
+}
+
+// Search @cat for templates matching @q, sorted by descending OS version, then by name.
+func (cat *TemplateCatalog) Find(q *TemplateQuery) (res []*CatalogEntry) {
+	for _, e := range cat.Entries {
+		if q.matches(e) {
+			res = append(res, e)
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return compareOSVersions(res[i].Info.Version, res[j].Info.Version) > 0
+	})
+
+	if q.Latest && len(res) > 0 {
+		var latest = res[0].Info.Version
+
+		for i := range res {
+			if compareOSVersions(res[i].Info.Version, latest) != 0 {
+				return res[:i]
+			}
+		}
+	}
+	return
+}
+
+// Return the catalog entries that are not available at every location of @cat.
+func (cat *TemplateCatalog) Incomplete() (res []*CatalogEntry) {
+	for _, e := range cat.Entries {
+		if len(e.MissingFrom) > 0 {
+			res = append(res, e)
+		}
+	}
+	return
+}
+
+// Compare OS versions such as "6", "2008 R2" or "2012": numeric components are compared
+// numerically, others lexically; an empty version is lower than any other.
+// Returns -1, 0 or 1 if @a is lower than, equal to, or higher than @b.
+func compareOSVersions(a, b string) int {
+	var fa, fb = strings.FieldsFunc(a, isVersionSeparator), strings.FieldsFunc(b, isVersionSeparator)
+
+	for i := 0; i < len(fa) && i < len(fb); i++ {
+		na, errA := strconv.Atoi(fa[i])
+		nb, errB := strconv.Atoi(fb[i])
+
+		if errA == nil && errB == nil {
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+		} else if c := strings.Compare(strings.ToUpper(fa[i]), strings.ToUpper(fb[i])); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(fa) < len(fb): return -1
+	case len(fa) > len(fb): return 1
+	}
+	return 0
+}
+
+func isVersionSeparator(r rune) bool {
+	return r == ' ' || r == '.'
+}
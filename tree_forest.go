@@ -0,0 +1,158 @@
+/*
+ * Account-wide view of the group hierarchies of all data centers (and sub-accounts).
+ */
+package clcv1
+
+import (
+	"strings"
+	"sort"
+	"sync"
+	"fmt"
+)
+
+// Options of GetGroupForest.
+type ForestOptions struct {
+	// Also populate the @Servers field of each group (see GetGroupHierarchy).
+	Servers		bool
+
+	// Also include the sub-accounts of the account (see GetAccounts).
+	SubAccounts	bool
+
+	// Maximum number of hierarchies to fetch concurrently (default 4).
+	Parallel	int
+}
+
+// Return true if @g is a synthetic node created by GetGroupForest, which does not
+// correspond to a hardware group (and hence has no UUID).
+func (g *GroupNode) IsSynthetic() bool {
+	return g.UUID == ""
+}
+
+// Create a synthetic node named @name.
+func newSyntheticNode(name string) *GroupNode {
+	return &GroupNode{ HardwareGroup: &HardwareGroup{ Name: name } }
+}
+
+// Fetch the group hierarchies of all data centers returned by GetLocations concurrently, and merge
+// them under a synthetic root node named after the account:
+//   <account> / [<sub-account> /] <location> / <root group of location> / ...
+// The sub-account level is only present with @opts.SubAccounts (one node per account, including the
+// account itself). Locations without groups are left out. The synthetic nodes do not appear in Path().
+// @acctAlias: The alias of the account (optional).
+// If some hierarchies could not be fetched, they are reported via @err, and left out of the forest;
+// if none could be fetched, @root is nil.
+func (c *Client) GetGroupForest(acctAlias string, opts ForestOptions) (root *GroupNode, err error) {
+	var mu       sync.Mutex
+	var wg       sync.WaitGroup
+	var errs     []string
+	var accounts = []string{ acctAlias }
+	var trees    = make(map[string][]*GroupNode)
+
+	locations, err := c.GetLocations()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list locations: %s", err)
+	}
+
+	if opts.SubAccounts {
+		if accounts, err = c.subAccounts(acctAlias); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Parallel < 1 {
+		opts.Parallel = 4
+	}
+	sem := make(chan struct{}, opts.Parallel)
+
+	for _, acct := range accounts {
+		for _, l := range locations {
+			wg.Add(1)
+			go func(acct, location string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				tree, err := c.groupHierarchy(location, acct, opts.Servers)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("%s: %s", strings.TrimLeft(acct + "/" + location, "/"), err))
+				} else if tree != nil {
+					trees[acct] = append(trees[acct], tree)
+				}
+			}(acct, l.Alias)
+		}
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		err = fmt.Errorf("Failed to fetch %d group hierarchies: %s", len(errs), strings.Join(errs, "; "))
+		if len(trees) == 0 {
+			return nil, err
+		}
+	}
+
+	if acctAlias == "" {
+		root = newSyntheticNode("All locations")
+	} else {
+		root = newSyntheticNode(acctAlias)
+	}
+	for _, acct := range accounts {
+		var parent = root
+
+		sort.Slice(trees[acct], func(i, j int) bool { return trees[acct][i].Location < trees[acct][j].Location })
+		if opts.SubAccounts {
+			parent = newSyntheticNode(acct)
+			parent.Parent = root
+			root.Children = append(root.Children, parent)
+		}
+		for _, tree := range trees[acct] {
+			loc := newSyntheticNode(tree.Location)
+			loc.Location    = tree.Location
+			loc.Parent      = parent
+			loc.Children    = []*GroupNode{ tree }
+			tree.Parent     = loc
+			parent.Children = append(parent.Children, loc)
+		}
+	}
+	return
+}
+
+// Return the alias of @acctAlias and of all of its (direct and indirect) sub-accounts, sorted by alias.
+// If @acctAlias is empty, all accounts visible to the API user are returned.
+func (c *Client) subAccounts(acctAlias string) (aliases []string, err error) {
+	var children = make(map[string][]string)
+	var seen     = make(map[string]bool)
+	var add      func(string)
+
+	accounts, err := c.GetAccounts()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list accounts: %s", err)
+	}
+
+	for _, a := range accounts {
+		if acctAlias == "" {
+			aliases = append(aliases, a.AccountAlias)
+		} else {
+			children[a.ParentAlias] = append(children[a.ParentAlias], a.AccountAlias)
+		}
+	}
+
+	add = func(alias string) {
+		if seen[alias] {
+			return
+		}
+		seen[alias] = true
+		aliases = append(aliases, alias)
+		for _, child := range children[alias] {
+			add(child)
+		}
+	}
+	if acctAlias != "" {
+		add(strings.ToUpper(acctAlias))
+	}
+	sort.Strings(aliases)
+	return
+}
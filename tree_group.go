@@ -5,6 +5,7 @@ package clcv1
 
 import (
 	"fmt"
+	"os"
 )
 
 type GroupNode struct {
@@ -14,6 +15,9 @@ type GroupNode struct {
 	// Link back to the upper level (nil if root node)
 	Parent		*GroupNode
 
+	// The data center location of the group
+	Location	string
+
 	// Folder/leaf elements - servers
 	Servers		[]*Server
 
@@ -26,16 +30,40 @@ type GroupNode struct {
 // @acctAlias: The alias of the account that owns the groups.
 // @servers:   If true, also populate the @Servers field of each group.
 func (c *Client) GetGroupHierarchy(location, acctAlias string, servers bool) (root *GroupNode, err error) {
+	if root, err = c.groupHierarchy(location, acctAlias, servers); err == nil && root == nil {
+		err = fmt.Errorf("No root hardware group found at %s", location)
+	}
+	return
+}
+
+// Implements GetGroupHierarchy, but returns a nil @root if there are no groups at @location.
+// The groups and servers are fetched concurrently.
+func (c *Client) groupHierarchy(location, acctAlias string, servers bool) (root *GroupNode, err error) {
 	var uuidMap = make(map[string]*GroupNode)
+	var srv     []Server
+	var srvErr  error
+	var done    = make(chan struct{})
 
-	if hwgroups, err := c.GetGroups(location, acctAlias); err != nil {
-		return nil, err
+	if servers {
+		go func() {
+			defer close(done)
+			srv, srvErr = c.GetAllServers(acctAlias, "", location)
+		}()
 	} else {
-		for i := range hwgroups {
-			uuidMap[hwgroups[i].UUID] = &GroupNode{ HardwareGroup: &hwgroups[i] }
-		}
+		close(done)
+	}
+
+	hwgroups, err := c.GetGroups(location, acctAlias)
+	<-done
+	if err != nil {
+		return nil, err
+	} else if srvErr != nil {
+		return nil, fmt.Errorf("Failed to look up servers at %s: %s", location, srvErr)
 	}
 
+	for i := range hwgroups {
+		uuidMap[hwgroups[i].UUID] = &GroupNode{ HardwareGroup: &hwgroups[i], Location: location }
+	}
 	for _, grp := range uuidMap {
 		if grp.ParentUUID == "" {
 			root = grp
@@ -47,23 +75,20 @@ func (c *Client) GetGroupHierarchy(location, acctAlias string, servers bool) (ro
 		}
 	}
 	if root == nil {
-		return nil, fmt.Errorf("No root hardware group found at %s", location)
+		if len(hwgroups) > 0 {
+			return nil, fmt.Errorf("No root hardware group found at %s", location)
+		}
+		return nil, nil
 	}
 
-	if servers {
-		srv, err := c.GetAllServers(acctAlias, "", location)
-		if err != nil {
-			return nil, fmt.Errorf("Failed to look up servers at %s: %s", location, err)
-		}
-		for i := range srv {
-			if group, ok := uuidMap[srv[i].HardwareGroupUUID]; !ok {
-				return nil, fmt.Errorf("Failed to look up HW Group UUID for %s", srv[i].Name)
-			} else {
-				group.Servers = append(group.Servers, &srv[i])
-			}
+	for i := range srv {
+		if group, ok := uuidMap[srv[i].HardwareGroupUUID]; !ok {
+			return nil, fmt.Errorf("Failed to look up HW Group UUID for %s", srv[i].Name)
+		} else {
+			group.Servers = append(group.Servers, &srv[i])
 		}
 	}
-	return
+	return root, nil
 }
 
 // Do a depth-first traversal of the tree to find a specific node.
@@ -81,29 +106,7 @@ func FindGroupNode(root *GroupNode, found func(*GroupNode) bool) *GroupNode {
 	return nil
 }
 
-// Print group hierarchy starting at @g, using initial indentation @indent.
+// Print group hierarchy starting at @g to stdout, using initial indentation @indent.
 func PrintGroupHierarchy(g *GroupNode, indent string) {
-	var groupLine string
-
-	if g.IsSystemGroup && g.ParentUUID != "" {
-		groupLine = fmt.Sprintf("%s[%s]/", indent, g.Name)
-	} else {
-		groupLine = fmt.Sprintf("%s%s/", indent, g.Name)
-	}
-	fmt.Printf("%-70s %s\n", groupLine, g.UUID)
-
-	for _, s := range g.Servers {
-		fmt.Printf("%s", indent + "    ")
-		if s.PowerState == "Started" {
-			fmt.Printf("*%s\n", s.Name)
-		} else if g.IsSystemGroup && g.Name == "Templates" {
-			fmt.Printf("%s\t%s\n", s.Name, s.Description)
-		} else {
-			fmt.Printf("%s\n", s.Name)
-		}
-	}
-
-	for _, c := range g.Children {
-		PrintGroupHierarchy(c, indent + "    ")
-	}
+	WriteGroupHierarchy(os.Stdout, g, indent)
 }
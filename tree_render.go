@@ -0,0 +1,334 @@
+/*
+ * Rendering of GroupNode trees as text, Unicode tree, nested JSON and Graphviz DOT.
+ */
+package clcv1
+
+import (
+	"text/tabwriter"
+	"encoding/json"
+	"strings"
+	"sync"
+	"fmt"
+	"io"
+)
+
+// The output formats supported by RenderGroupHierarchy.
+var GroupFormats = []string{ "text", "tree", "json", "dot" }
+
+// Render the hierarchy starting at @g to @w in @format (one of GroupFormats).
+// @opts: The columns to show in "tree" format (ignored by the other formats).
+func RenderGroupHierarchy(w io.Writer, g *GroupNode, format string, opts TreeOptions) error {
+	switch strings.ToLower(format) {
+	case "text", "":
+		return WriteGroupHierarchy(w, g, "")
+	case "tree":
+		return RenderGroupTree(w, g, opts)
+	case "json":
+		return RenderGroupJSON(w, g)
+	case "dot":
+		return RenderGroupDOT(w, g)
+	}
+	return fmt.Errorf("Unsupported group format %q (expected one of %s)", format, strings.Join(GroupFormats, ", "))
+}
+
+// Write group hierarchy starting at @g to @w, using initial indentation @indent (see PrintGroupHierarchy).
+func WriteGroupHierarchy(w io.Writer, g *GroupNode, indent string) (err error) {
+	var groupLine string
+
+	if g.IsSystemGroup && g.ParentUUID != "" {
+		groupLine = fmt.Sprintf("%s[%s]/", indent, g.Name)
+	} else {
+		groupLine = fmt.Sprintf("%s%s/", indent, g.Name)
+	}
+	if _, err = fmt.Fprintf(w, "%-70s %s\n", groupLine, g.UUID); err != nil {
+		return err
+	}
+
+	for _, s := range g.Servers {
+		if s.PowerState == PowerStarted {
+			_, err = fmt.Fprintf(w, "%s*%s\n", indent + "    ", s.Name)
+		} else if g.IsSystemGroup && g.Name == "Templates" {
+			_, err = fmt.Fprintf(w, "%s%s\t%s\n", indent + "    ", s.Name, s.Description)
+		} else {
+			_, err = fmt.Fprintf(w, "%s%s\n", indent + "    ", s.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, c := range g.Children {
+		if err = WriteGroupHierarchy(w, c, indent + "    "); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Optional columns of the Unicode tree (see RenderGroupTree).
+type TreeColumn int
+
+const (
+	// Power state; for groups the number of started servers.
+	TreePower TreeColumn = iota
+
+	// Number of CPUs and memory; for groups the totals.
+	TreeCpuMem
+
+	// Primary IP address (servers only).
+	TreeIP
+
+	// Estimated monthly cost (requires TreeOptions.Costs); for groups the total.
+	TreeCost
+)
+
+var treeColumnNames = map[string]TreeColumn{ "power": TreePower, "cpu": TreeCpuMem, "ip": TreeIP, "cost": TreeCost }
+
+// Parse a comma-separated list of tree columns: power, cpu, ip, cost.
+func ParseTreeColumns(s string) (cols []TreeColumn, err error) {
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name == "" {
+			continue
+		} else if col, ok := treeColumnNames[name]; !ok {
+			return nil, fmt.Errorf("Invalid tree column %q (expected power, cpu, ip or cost)", name)
+		} else {
+			cols = append(cols, col)
+		}
+	}
+	return
+}
+
+// Options of RenderGroupTree.
+type TreeOptions struct {
+	// The columns to show after each name.
+	Columns		[]TreeColumn
+
+	// Estimated monthly cost, indexed by server name (see GetServerCosts).
+	// Servers that are missing are shown without cost.
+	Costs		map[string]float64
+}
+
+// Render the hierarchy starting at @g as a Unicode box-drawing tree, with the columns selected in @opts.
+// Within each group, servers are listed before sub-groups; group columns show subtree totals.
+func RenderGroupTree(w io.Writer, g *GroupNode, opts TreeOptions) error {
+	var tw    = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	var stats = g.Rollup()
+	var costs = make(map[*GroupNode]float64)
+	var node  func(g *GroupNode, prefix, branch string)
+
+	g.Walk(PostOrder, func(n *GroupNode, _ int) WalkAction {
+		for _, s := range n.Servers {
+			costs[n] += opts.Costs[s.Name]
+		}
+		for _, c := range n.Children {
+			costs[n] += costs[c]
+		}
+		return WalkContinue
+	})
+
+	row := func(name string, cols ...string) {
+		fmt.Fprintln(tw, strings.TrimRight(strings.Join(append([]string{ name }, cols...), "\t"), "\t"))
+	}
+
+	groupCols := func(n *GroupNode) (cols []string) {
+		st := stats[n]
+		for _, col := range opts.Columns {
+			switch col {
+			case TreePower:  cols = append(cols, fmt.Sprintf("%d/%d started", st.Started, st.Servers))
+			case TreeCpuMem: cols = append(cols, fmt.Sprintf("%d CPU/%dGB", st.Cpu, st.MemoryGB))
+			case TreeIP:     cols = append(cols, "")
+			case TreeCost:   cols = append(cols, fmt.Sprintf("$%.2f", costs[n]))
+			}
+		}
+		return
+	}
+
+	serverCols := func(s *Server) (cols []string) {
+		for _, col := range opts.Columns {
+			switch col {
+			case TreePower:
+				cols = append(cols, s.PowerState.String())
+			case TreeCpuMem:
+				cols = append(cols, fmt.Sprintf("%d CPU/%dGB", s.Cpu, s.MemoryGB))
+			case TreeIP:
+				cols = append(cols, s.IPAddress)
+			case TreeCost:
+				if cost, ok := opts.Costs[s.Name]; ok {
+					cols = append(cols, fmt.Sprintf("$%.2f", cost))
+				} else {
+					cols = append(cols, "")
+				}
+			}
+		}
+		return
+	}
+
+	// @prefix: the indentation of the children of the parent, @branch: the connector to @n.
+	node = func(n *GroupNode, prefix, branch string) {
+		var name = n.Name + "/"
+		var inner = prefix
+
+		if n.IsSystemGroup && n.ParentUUID != "" {
+			name = "[" + n.Name + "]/"
+		}
+		row(prefix + branch + name, groupCols(n)...)
+
+		switch branch {
+		case "├── ": inner += "│   "
+		case "└── ": inner += "    "
+		}
+
+		for i, s := range n.Servers {
+			var conn = "├── "
+
+			if i == len(n.Servers) - 1 && len(n.Children) == 0 {
+				conn = "└── "
+			}
+			row(inner + conn + s.Name, serverCols(s)...)
+		}
+		for i, c := range n.Children {
+			if i == len(n.Children) - 1 {
+				node(c, inner, "└── ")
+			} else {
+				node(c, inner, "├── ")
+			}
+		}
+	}
+	node(g, "", "")
+	return tw.Flush()
+}
+
+// JSON representation of a group and its contents.
+type groupTreeJSON struct {
+	Name		string
+	UUID		string
+	Path		string
+	Location	string
+	IsSystemGroup	bool
+	Servers		[]*Server
+	Groups		[]*groupTreeJSON
+}
+
+func newGroupTreeJSON(g *GroupNode) *groupTreeJSON {
+	var j = &groupTreeJSON{
+		Name:          g.Name,
+		UUID:          g.UUID,
+		Path:          g.Path(),
+		Location:      g.Location,
+		IsSystemGroup: g.IsSystemGroup,
+		Servers:       g.Servers,
+		Groups:        []*groupTreeJSON{},
+	}
+	if j.Servers == nil {
+		j.Servers = []*Server{}
+	}
+	for _, c := range g.Children {
+		j.Groups = append(j.Groups, newGroupTreeJSON(c))
+	}
+	return j
+}
+
+// Render the hierarchy starting at @g as nested JSON: each group has its Servers and sub-Groups.
+func RenderGroupJSON(w io.Writer, g *GroupNode) error {
+	data, err := json.MarshalIndent(newGroupTreeJSON(g), "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// Quote @s as DOT string.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// Render the hierarchy starting at @g as Graphviz DOT graph (e.g. for 'dot -Tsvg'):
+// groups are nested clusters, and servers are nodes (filled green if started).
+func RenderGroupDOT(w io.Writer, g *GroupNode) error {
+	var b strings.Builder
+	var id  int
+	var cluster func(n *GroupNode, indent string)
+
+	cluster = func(n *GroupNode, indent string) {
+		/* Not using the UUID, since synthetic nodes (see GetGroupForest) do not have one */
+		id++
+		fmt.Fprintf(&b, "%ssubgraph cluster_%d {\n", indent, id)
+		fmt.Fprintf(&b, "%s\tlabel=%s;\n", indent, dotQuote(n.Name))
+		if n.IsSystemGroup {
+			fmt.Fprintf(&b, "%s\tstyle=dashed;\n", indent)
+		}
+		if len(n.Servers) == 0 && len(n.Children) == 0 {
+			/* Graphviz does not draw empty clusters */
+			fmt.Fprintf(&b, "%s\tempty_%d [label=\"\", shape=point, style=invis];\n", indent, id)
+		}
+		for _, s := range n.Servers {
+			var attrs = "shape=box"
+
+			if s.IsTemplate {
+				attrs += ", style=dashed"
+			} else if s.PowerState == PowerStarted {
+				attrs += ", style=filled, fillcolor=palegreen"
+			} else {
+				attrs += ", style=filled, fillcolor=lightgrey"
+			}
+			fmt.Fprintf(&b, "%s\t%s [label=%s, %s];\n", indent, dotQuote(s.Name),
+				    dotQuote(fmt.Sprintf("%s\n%s", s.Name, s.PowerState)), attrs)
+		}
+		for _, c := range n.Children {
+			cluster(c, indent + "\t")
+		}
+		fmt.Fprintf(&b, "%s}\n", indent)
+	}
+
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(g.Name))
+	fmt.Fprintf(&b, "\tnode [fontsize=10];\n")
+	cluster(g, "\t")
+	fmt.Fprintf(&b, "}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Look up the estimated monthly cost of @servers (templates are skipped), using up to @parallel
+// concurrent requests. Returns the costs by server name (see TreeOptions.Costs).
+// @acctAlias: The alias of the account that owns the servers (optional).
+func (c *Client) GetServerCosts(servers []*Server, acctAlias string, parallel int) (map[string]float64, error) {
+	var costs = make(map[string]float64)
+	var errs  []string
+	var mu    sync.Mutex
+	var wg    sync.WaitGroup
+
+	if parallel < 1 {
+		parallel = 1
+	}
+	sem := make(chan struct{}, parallel)
+
+	for _, s := range servers {
+		if s.IsTemplate {
+			continue
+		}
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			est, err := c.GetServerEstimate(name, acctAlias)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", name, err))
+			} else {
+				costs[name] = est.MonthlyEstimate
+			}
+		}(s.Name)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return costs, fmt.Errorf("Failed to get cost estimate of %s", strings.Join(errs, "; "))
+	}
+	return costs, nil
+}
@@ -0,0 +1,216 @@
+/*
+ * Traversal, filtering and rollup statistics of GroupNode trees.
+ */
+package clcv1
+
+// Controls how Walk proceeds after visiting a group.
+type WalkAction int
+
+const (
+	// Continue the traversal.
+	WalkContinue WalkAction = iota
+
+	// Do not descend into the sub-groups of this group (pre-order only; in post-order
+	// the sub-groups have already been visited, and this is the same as WalkContinue).
+	WalkSkip
+
+	// Stop the traversal.
+	WalkStop
+)
+
+// The order in which Walk visits the groups.
+type WalkOrder int
+
+const (
+	// Visit a group before its sub-groups.
+	PreOrder WalkOrder = iota
+
+	// Visit a group after its sub-groups (e.g. to compute aggregates bottom-up).
+	PostOrder
+)
+
+// Called by Walk for each group @g, where @depth is 0 for the group the walk started at.
+type GroupVisitor func(g *GroupNode, depth int) WalkAction
+
+// Walk the tree rooted at @g depth-first in @order, calling @visit for each group.
+// Returns false if the traversal was stopped by @visit returning WalkStop.
+func (g *GroupNode) Walk(order WalkOrder, visit GroupVisitor) bool {
+	return g.walk(order, visit, 0) != WalkStop
+}
+
+func (g *GroupNode) walk(order WalkOrder, visit GroupVisitor, depth int) WalkAction {
+	if order == PreOrder {
+		switch visit(g, depth) {
+		case WalkStop:
+			return WalkStop
+		case WalkSkip:
+			return WalkContinue
+		}
+	}
+
+	for _, c := range g.Children {
+		if c.walk(order, visit, depth + 1) == WalkStop {
+			return WalkStop
+		}
+	}
+
+	if order == PostOrder && visit(g, depth) == WalkStop {
+		return WalkStop
+	}
+	return WalkContinue
+}
+
+// Return the servers of @g and all of its sub-groups, in pre-order.
+// @templates: whether to include templates.
+func (g *GroupNode) AllServers(templates bool) (servers []*Server) {
+	g.Walk(PreOrder, func(n *GroupNode, _ int) WalkAction {
+		for _, s := range n.Servers {
+			if templates || !s.IsTemplate {
+				servers = append(servers, s)
+			}
+		}
+		return WalkContinue
+	})
+	return
+}
+
+// Return a copy of the tree rooted at @g that contains only the groups matching @match,
+// along with their ancestors (up to @g), so that each match keeps its position in the tree.
+// Returns nil if no group matches.
+// The copy shares the HardwareGroup and Server values of the original; its root keeps the
+// Parent of @g, so that Path() still returns the full path.
+func (g *GroupNode) FilterGroups(match func(*GroupNode) bool) *GroupNode {
+	return g.filter(match, nil)
+}
+
+// Return a copy of the tree rooted at @g in which each group contains only the servers matching
+// @match. Groups that have no matching servers in their subtree are dropped.
+// Returns nil if no server matches. See also FilterGroups.
+func (g *GroupNode) FilterServers(match func(*Server) bool) *GroupNode {
+	return g.filter(nil, match)
+}
+
+// Copy @g, keeping groups that match @matchGroup (if non-nil) and servers that match
+// @matchServer (if non-nil). A group without any matches in its subtree is dropped.
+func (g *GroupNode) filter(matchGroup func(*GroupNode) bool, matchServer func(*Server) bool) *GroupNode {
+	var node = &GroupNode{ HardwareGroup: g.HardwareGroup, Parent: g.Parent, Location: g.Location }
+	var keep = matchGroup != nil && matchGroup(g)
+
+	if matchServer == nil {
+		node.Servers = g.Servers
+	} else {
+		for _, s := range g.Servers {
+			if matchServer(s) {
+				node.Servers = append(node.Servers, s)
+			}
+		}
+		keep = len(node.Servers) > 0
+	}
+
+	for _, c := range g.Children {
+		if child := c.filter(matchGroup, matchServer); child != nil {
+			child.Parent  = node
+			node.Children = append(node.Children, child)
+		}
+	}
+	if !keep && len(node.Children) == 0 {
+		return nil
+	}
+	return node
+}
+
+// Aggregate statistics of the servers in a group and its sub-groups.
+// Templates are only counted in @Templates, and not included in any of the other figures.
+type GroupStats struct {
+	// Number of groups, including the group itself (synthetic nodes are not counted).
+	Groups		int
+
+	// Number of servers (excluding templates).
+	Servers		int
+
+	// Number of servers by power state.
+	Started		int
+	Stopped		int
+	Paused		int
+
+	// Number of templates.
+	Templates	int
+
+	// Total number of CPUs, memory and disk space.
+	Cpu		int
+	MemoryGB	int
+	DiskGB		int
+
+	// Number of servers by operating system.
+	OS		map[OperatingSystem]int
+}
+
+// Add server @s to @st.
+func (st *GroupStats) addServer(s *Server) {
+	if s.IsTemplate {
+		st.Templates++
+		return
+	}
+	st.Servers++
+	switch s.PowerState {
+	case PowerStarted: st.Started++
+	case PowerStopped: st.Stopped++
+	case PowerPaused:  st.Paused++
+	}
+	st.Cpu      += s.Cpu
+	st.MemoryGB += s.MemoryGB
+	st.DiskGB   += s.TotalDiskSpaceGB
+
+	if st.OS == nil {
+		st.OS = make(map[OperatingSystem]int)
+	}
+	st.OS[s.OperatingSystem]++
+}
+
+// Add the statistics @o to @st.
+func (st *GroupStats) Add(o *GroupStats) {
+	st.Groups    += o.Groups
+	st.Servers   += o.Servers
+	st.Started   += o.Started
+	st.Stopped   += o.Stopped
+	st.Paused    += o.Paused
+	st.Templates += o.Templates
+	st.Cpu       += o.Cpu
+	st.MemoryGB  += o.MemoryGB
+	st.DiskGB    += o.DiskGB
+
+	for sys, n := range o.OS {
+		if st.OS == nil {
+			st.OS = make(map[OperatingSystem]int)
+		}
+		st.OS[sys] += n
+	}
+}
+
+// Return the statistics of @g, including all sub-groups.
+// Requires the tree to have been loaded with servers (see GetGroupHierarchy).
+func (g *GroupNode) Stats() *GroupStats {
+	return g.Rollup()[g]
+}
+
+// Compute the statistics of every subtree of @g in a single bottom-up pass.
+// Returns the statistics of each group (including its sub-groups), indexed by group.
+func (g *GroupNode) Rollup() map[*GroupNode]*GroupStats {
+	var stats = make(map[*GroupNode]*GroupStats)
+
+	g.Walk(PostOrder, func(n *GroupNode, _ int) WalkAction {
+		st := &GroupStats{ Groups: 1 }
+		if n.IsSynthetic() {
+			st.Groups = 0
+		}
+		for _, s := range n.Servers {
+			st.addServer(s)
+		}
+		for _, c := range n.Children {
+			st.Add(stats[c])
+		}
+		stats[n] = st
+		return WalkContinue
+	})
+	return stats
+}
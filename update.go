@@ -0,0 +1,179 @@
+/*
+ * Patch-style server updates on top of ConfigureServer.
+ */
+package clcv1
+
+import (
+	"strconv"
+	"fmt"
+)
+
+// An in-progress update of a server: the current state, and the request being built from it.
+type serverUpdate struct {
+	client		*Client
+	acctAlias	string
+
+	// Resolver for custom field names (loaded on demand).
+	resolver	*CustomFieldResolver
+
+	// The request, initialized from the current state of the server.
+	req		ConfigureServerReq
+}
+
+// Load the custom field resolver of @u, if not done already.
+func (u *serverUpdate) customFields() (*CustomFieldResolver, error) {
+	if u.resolver == nil {
+		r, err := u.client.LoadCustomFieldResolver(u.acctAlias)
+		if err != nil {
+			return nil, err
+		}
+		u.resolver = r
+	}
+	return u.resolver, nil
+}
+
+// A single change applied by UpdateServer.
+type UpdateOption func(*serverUpdate) error
+
+// Set the number of CPUs.
+func WithCpu(cpu int) UpdateOption {
+	return func(u *serverUpdate) error {
+		u.req.Cpu = cpu
+		return nil
+	}
+}
+
+// Set the amount of memory in GB.
+func WithMemoryGB(memGB int) UpdateOption {
+	return func(u *serverUpdate) error {
+		u.req.MemoryGB = memGB
+		return nil
+	}
+}
+
+// Move the server to the hardware group @hwGrpUUID.
+func WithGroup(hwGrpUUID string) UpdateOption {
+	return func(u *serverUpdate) error {
+		if hwGrpUUID == "" {
+			return fmt.Errorf("Empty hardware group UUID")
+		}
+		u.req.HardwareGroupUUID = hwGrpUUID
+		return nil
+	}
+}
+
+// Add an additional drive of @sizeGB to the server.
+func WithAdditionalStorageGB(sizeGB int) UpdateOption {
+	return func(u *serverUpdate) error {
+		if sizeGB < 0 {
+			return fmt.Errorf("Invalid additional storage size %d GB", sizeGB)
+		}
+		u.req.AdditionalStorageGB = sizeGB
+		return nil
+	}
+}
+
+// Set the custom field @name to @value (see CustomFieldResolver.Value).
+func WithCustomField(name, value string) UpdateOption {
+	return func(u *serverUpdate) error {
+		r, err := u.customFields()
+		if err != nil {
+			return err
+		}
+		u.req.CustomFields, err = r.Set(u.req.CustomFields, name, value)
+		return err
+	}
+}
+
+// Set several custom fields (name -> value) at once.
+func WithCustomFields(fields map[string]string) UpdateOption {
+	return func(u *serverUpdate) error {
+		for name, value := range fields {
+			if err := WithCustomField(name, value)(u); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Update server @name by applying @opts to its current configuration.
+// @acctAlias: The alias of the account that owns the server (optional).
+// Settings not mentioned in @opts (group, CPU, memory, custom fields) are preserved.
+// The changed settings are validated before submitting them; if the result does not differ from the
+// current one, nothing is submitted and @reqId is 0.
+// Returns the changes made, and the request ID of ConfigureServer.
+func (c *Client) UpdateServer(name, acctAlias string, opts ...UpdateOption) (changes []FieldChange, reqId int, err error) {
+	var v ValidationError
+
+	server, err := c.GetServer(name, acctAlias)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Failed to look up server %s: %s", name, err)
+	}
+
+	u := &serverUpdate{
+		client:    c,
+		acctAlias: acctAlias,
+		req:       ConfigureServerReq{
+			Name:              server.Name,
+			HardwareGroupUUID: server.HardwareGroupUUID,
+			AccountAlias:      acctAlias,
+			Cpu:               server.Cpu,
+			MemoryGB:          server.MemoryGB,
+			CustomFields:      ServerCustomFieldValues(server.CustomFields),
+		},
+	}
+	for _, opt := range opts {
+		if err := opt(u); err != nil {
+			v.add("%s", err)
+		}
+	}
+	/* Only check what changes, so that unrelated updates are not blocked by the current values */
+	if u.req.Cpu != server.Cpu {
+		validateCpu(&v, u.req.Cpu)
+	}
+	if u.req.MemoryGB != server.MemoryGB {
+		validateMemory(&v, u.req.MemoryGB)
+	}
+	if err = v.err(); err != nil {
+		return nil, 0, err
+	}
+
+	if changes = u.diff(&server); len(changes) == 0 {
+		return nil, 0, nil
+	}
+	reqId, err = c.ConfigureServer(&u.req)
+	return
+}
+
+// Return the changes of @u relative to the current state of @server.
+func (u *serverUpdate) diff(server *Server) (changes []FieldChange) {
+	var old = make(map[string]string)
+
+	if u.req.HardwareGroupUUID != server.HardwareGroupUUID {
+		changes = append(changes, FieldChange{ Field: "HardwareGroupUUID", Old: server.HardwareGroupUUID, New: u.req.HardwareGroupUUID })
+	}
+	if u.req.Cpu != server.Cpu {
+		changes = append(changes, FieldChange{ Field: "Cpu", Old: strconv.Itoa(server.Cpu), New: strconv.Itoa(u.req.Cpu) })
+	}
+	if u.req.MemoryGB != server.MemoryGB {
+		changes = append(changes, FieldChange{ Field: "MemoryGB", Old: strconv.Itoa(server.MemoryGB), New: strconv.Itoa(u.req.MemoryGB) })
+	}
+	if u.req.AdditionalStorageGB != 0 {
+		changes = append(changes, FieldChange{ Field: "AdditionalStorageGB", Old: "", New: strconv.Itoa(u.req.AdditionalStorageGB) })
+	}
+
+	for _, f := range server.CustomFields {
+		old[f.ID] = f.Value
+	}
+	for _, f := range u.req.CustomFields {
+		if prev := old[f.ID]; prev != f.Value {
+			if u.resolver != nil {
+				changes = append(changes, u.resolver.fieldChange(f.ID, prev, f.Value))
+			} else {
+				changes = append(changes, FieldChange{ Field: f.ID, Old: prev, New: f.Value })
+			}
+		}
+	}
+	return
+}
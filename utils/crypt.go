@@ -0,0 +1,107 @@
+/*
+ * Passphrase-based encryption of files (scrypt + AES-256-GCM).
+ */
+package utils
+
+import (
+	"golang.org/x/crypto/scrypt"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/aes"
+	"io/ioutil"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+/* File format: magic | salt | nonce | ciphertext */
+var cryptMagic = []byte("CLCENC1\n")
+
+const cryptSaltLen = 16
+
+// Derive the AES-GCM cipher for @passphrase and @salt.
+func cryptCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to derive key: %s", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt @data with @passphrase and write the result to @path (mode 0600).
+// Refuses to overwrite an existing file; use EncryptTo with a file opened in advance
+// to make sure that the output can be written before producing the data.
+func EncryptToFile(path, passphrase string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	if err = EncryptTo(f, passphrase, data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// Encrypt @data with @passphrase and write the result to @w, in the format read by DecryptFile.
+func EncryptTo(w io.Writer, passphrase string, data []byte) error {
+	var salt = make([]byte, cryptSaltLen)
+
+	if passphrase == "" {
+		return fmt.Errorf("Passphrase must not be empty")
+	} else if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return fmt.Errorf("Failed to generate salt: %s", err)
+	}
+
+	gcm, err := cryptCipher(passphrase, salt)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("Failed to generate nonce: %s", err)
+	}
+
+	out := append(append(append([]byte{}, cryptMagic...), salt...), nonce...)
+	out  = gcm.Seal(out, nonce, data, cryptMagic)
+	_, err = w.Write(out)
+	return err
+}
+
+// Read @path, as written by EncryptToFile, and decrypt its contents using @passphrase.
+func DecryptFile(path, passphrase string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	} else if !bytes.HasPrefix(data, cryptMagic) {
+		return nil, fmt.Errorf("%s is not an encrypted file", path)
+	}
+	data = data[len(cryptMagic):]
+
+	if len(data) < cryptSaltLen {
+		return nil, fmt.Errorf("%s is truncated", path)
+	}
+	gcm, err := cryptCipher(passphrase, data[:cryptSaltLen])
+	if err != nil {
+		return nil, err
+	}
+	data = data[cryptSaltLen:]
+
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("%s is truncated", path)
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], cryptMagic)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decrypt %s (wrong passphrase?)", path)
+	}
+	return plain, nil
+}
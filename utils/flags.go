@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"strings"
+	"sort"
+	"fmt"
+)
+
+// Repeatable commandline flag collecting <key>=<value> pairs, for use with flag.Var.
+type KeyValueFlag map[string]string
+
+func (m KeyValueFlag) String() string {
+	var pairs []string
+
+	for k, v := range m {
+		pairs = append(pairs, k + "=" + v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (m KeyValueFlag) Set(s string) error {
+	idx := strings.Index(s, "=")
+	if idx <= 0 {
+		return fmt.Errorf("Invalid argument %q (expected <key>=<value>)", s)
+	}
+	m[strings.TrimSpace(s[:idx])] = s[idx+1:]
+	return nil
+}
@@ -0,0 +1,244 @@
+/*
+ * Pre-flight validation of requests, to catch errors before they come back as StatusCodes.
+ */
+package clcv1
+
+import (
+	"strings"
+	"regexp"
+	"fmt"
+)
+
+// Server configuration limits of the v1 API.
+// Upper limits (CPU, memory, extra drive size) are not documented, and are left to the API to enforce.
+const (
+	MinCpu		= 1
+	MinMemoryGB	= 1
+
+	// Maximum length of the server alias (StatusCode 503 if exceeded).
+	MaxAliasLength	= 6
+)
+
+var aliasRegexp = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+// ValidationError collects all problems found while validating a request.
+type ValidationError []string
+
+func (v ValidationError) Error() string {
+	if len(v) == 1 {
+		return v[0]
+	}
+	return fmt.Sprintf("%d problems: %s", len(v), strings.Join(v, "; "))
+}
+
+// Add a problem to @v.
+func (v *ValidationError) add(format string, a ...interface{}) {
+	*v = append(*v, fmt.Sprintf(format, a...))
+}
+
+// Return @v as error, or nil if there are no problems.
+func (v ValidationError) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+// Check the CPU value against the lower limit of the v1 API.
+func validateCpu(v *ValidationError, cpu int) {
+	if cpu < MinCpu {
+		v.add("Invalid CPU value %d (must be at least %d)", cpu, MinCpu)
+	}
+}
+
+// Check the memory value against the lower limit of the v1 API.
+func validateMemory(v *ValidationError, memGB int) {
+	if memGB < MinMemoryGB {
+		v.add("Invalid memory value %d GB (must be at least %d)", memGB, MinMemoryGB)
+	}
+}
+
+// Validate @r locally, without contacting the API. Returns a ValidationError listing all problems.
+// Since the template OS is not known locally, the password is checked against DefaultPasswordPolicy,
+// which satisfies the requirements of all operating systems; see ValidateCreateServer.
+func (r *CreateServerReq) Validate() error {
+	return r.validate(&DefaultPasswordPolicy)
+}
+
+// Validate @r locally, checking the password against @pwPolicy.
+func (r *CreateServerReq) validate(pwPolicy *PasswordPolicy) error {
+	var v ValidationError
+
+	if r.Template == "" {
+		v.add("Template name required")
+	}
+
+	if r.Alias == "" {
+		v.add("Alias required")
+	} else if len(r.Alias) > MaxAliasLength {
+		v.add("Alias %q too long (limit is %d characters)", r.Alias, MaxAliasLength)
+	} else if !aliasRegexp.MatchString(r.Alias) {
+		v.add("Alias %q must consist of letters and digits only", r.Alias)
+	}
+
+	if r.HardwareGroupUUID == "" {
+		v.add("Hardware Group ID required")
+	}
+
+	if !r.ServerType.Valid() {
+		v.add("Invalid server type %d (must be 1 = Standard or 2 = Enterprise)", int(r.ServerType))
+	}
+	if !r.ServiceLevel.Valid() {
+		v.add("Invalid service level %d (must be 1 = Premium or 2 = Standard)", int(r.ServiceLevel))
+	}
+
+	validateCpu(&v, r.Cpu)
+	validateMemory(&v, r.MemoryGB)
+
+	/* The upper limit is not documented; the API reports it as StatusCode 1413 */
+	if r.ExtraDriveGB < 0 {
+		v.add("Invalid extra drive size %d GB", r.ExtraDriveGB)
+	}
+
+	/* An empty password means that the system generates one */
+	if r.Password != "" {
+		if err := pwPolicy.Check(r.Password); err != nil {
+			v = append(v, err.(ValidationError)...)
+		}
+	}
+	return v.err()
+}
+
+// Validate @req locally and against the account: checks that the template is available at the
+// location, the network is deployable, the hardware group exists, and that the custom fields
+// are defined on the account, have valid values, and that all required fields are present.
+// Returns a ValidationError listing all problems found.
+func (c *Client) ValidateCreateServer(req *CreateServerReq) error {
+	var v ValidationError
+	var where = req.LocationAlias
+
+	if where == "" {
+		where = "the default location"
+	}
+
+	var pwPolicy = &DefaultPasswordPolicy
+
+	if req.Template != "" {
+		if templates, err := c.ListAvailableServerTemplates(req.AccountAlias, req.LocationAlias); err != nil {
+			v.add("Failed to list templates: %s", err)
+		} else if t := findTemplate(templates, req.Template); t == nil {
+			v.add("Template %q is not available at %s", req.Template, where)
+		} else {
+			pwPolicy = PasswordPolicyFor(t.OperatingSystem)
+		}
+	}
+
+	if err := req.validate(pwPolicy); err != nil {
+		v = append(v, err.(ValidationError)...)
+	}
+
+	if nets, err := c.GetDeployableNetworks(req.AccountAlias, req.LocationAlias); err != nil {
+		v.add("Failed to list deployable networks: %s", err)
+	} else if req.Network == "" {
+		if len(nets) > 0 {
+			v.add("Network required (%d networks deployable at %s)", len(nets), where)
+		}
+	} else if !networkExists(nets, req.Network) {
+		v.add("Network %q is not deployable at %s", req.Network, where)
+	}
+
+	if req.HardwareGroupUUID != "" {
+		if groups, err := c.GetGroups(req.LocationAlias, req.AccountAlias); err != nil {
+			v.add("Failed to look up hardware groups: %s", err)
+		} else if !groupExists(groups, req.HardwareGroupUUID) {
+			v.add("Hardware group %s does not exist at %s", req.HardwareGroupUUID, where)
+		}
+	}
+
+	if fields, err := c.GetCustomFields(req.AccountAlias); err != nil {
+		v.add("Failed to look up custom fields: %s", err)
+	} else {
+		validateCustomFields(&v, fields, req.CustomFields)
+	}
+	return v.err()
+}
+
+// Return the template named @name in @templates, or nil if not found.
+func findTemplate(templates []ServerTemplate, name string) *ServerTemplate {
+	for i := range templates {
+		if strings.EqualFold(templates[i].Name, name) {
+			return &templates[i]
+		}
+	}
+	return nil
+}
+
+// Return true if a group with @uuid is in @groups.
+func groupExists(groups []HardwareGroup, uuid string) bool {
+	for _, g := range groups {
+		if g.UUID == uuid {
+			return true
+		}
+	}
+	return false
+}
+
+// Return true if a network named @name is in @nets.
+func networkExists(nets []Network, name string) bool {
+	for _, n := range nets {
+		if strings.EqualFold(n.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check the custom field @values against the account definitions @defs.
+func validateCustomFields(v *ValidationError, defs []AccountCustomField, values []CustomFieldValue) {
+	var set = make(map[string]string)
+
+	for _, f := range values {
+		set[f.ID] = f.Value
+	}
+
+	for _, d := range defs {
+		val, ok := set[d.UUID]
+		if !ok || val == "" {
+			if d.IsRequired {
+				v.add("Custom field %q is required", d.Name)
+			}
+			delete(set, d.UUID)
+			continue
+		}
+		delete(set, d.UUID)
+
+		if err := checkCustomFieldValue(&d, val); err != nil {
+			v.add("%s", err)
+		}
+	}
+
+	for id := range set {
+		v.add("Custom field %s is not defined on the account", id)
+	}
+}
+
+// Check @val against the type of the custom field definition @d.
+func checkCustomFieldValue(d *AccountCustomField, val string) error {
+	switch d.CustomFieldType {
+	case "Checkbox":
+		if val != "true" && val != "false" {
+			return fmt.Errorf("Custom field %q: checkbox value must be \"true\" or \"false\", not %q", d.Name, val)
+		}
+	case "Option":
+		var options []string
+
+		for _, o := range d.AccountCustomFieldOptions {
+			if o.Value == val {
+				return nil
+			}
+			options = append(options, o.Value)
+		}
+		return fmt.Errorf("Custom field %q: invalid option %q (valid: %s)", d.Name, val, strings.Join(options, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+/*
+ * Periodic refresh of server state, reporting what changed since the previous refresh.
+ */
+package clcv1
+
+import (
+	"reflect"
+	"strings"
+	"sort"
+	"time"
+	"fmt"
+)
+
+// Result of a single Watcher refresh.
+type WatchUpdate struct {
+	// When the refresh happened.
+	When		time.Time
+
+	// The current state of the watched servers, sorted by name.
+	Servers		[]Server
+
+	// Field-level changes since the previous refresh, indexed by server name.
+	Changes		map[string][]FieldChange
+
+	// Servers that appeared/disappeared since the previous refresh.
+	Added		[]string
+	Removed		[]string
+}
+
+// Return true if field @field of server @name changed in @u.
+func (u *WatchUpdate) Changed(name, field string) bool {
+	for _, f := range u.Changes[name] {
+		if f.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// A condition to wait for, evaluated against the current state of the watched servers.
+type WatchCondition func(servers []Server) bool
+
+// Parse a condition of the form "<field>=<value>", which holds if the Server field
+// (e.g. PowerState, Status, InMaintenanceMode) of all watched servers has the value @value
+// (case-insensitive). The shorthands "power" and "status" may be used for PowerState/Status.
+// The condition does not hold if there are no servers.
+func ParseWatchCondition(s string) (WatchCondition, error) {
+	var aliases = map[string]string{ "power": "PowerState", "status": "Status", "maintenance": "InMaintenanceMode" }
+
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 {
+		return nil, fmt.Errorf("Invalid condition %q (expected <field>=<value>)", s)
+	}
+	field, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+	if alias, ok := aliases[strings.ToLower(field)]; ok {
+		field = alias
+	}
+
+	sf, ok := reflect.TypeOf(Server{}).FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, field) })
+	if !ok {
+		return nil, fmt.Errorf("Invalid condition %q: Server has no field %q", s, field)
+	}
+
+	return func(servers []Server) bool {
+		for i := range servers {
+			v := reflect.ValueOf(servers[i]).FieldByIndex(sf.Index)
+			if !strings.EqualFold(fmt.Sprint(v.Interface()), value) {
+				return false
+			}
+		}
+		return len(servers) > 0
+	}, nil
+}
+
+// Watcher refreshes the state of a set of servers, and computes the changes between refreshes.
+type Watcher struct {
+	// Returns the current state of the watched servers.
+	fetch		func() ([]Server, error)
+
+	// State of the previous refresh (nil before the first one).
+	last		map[string]Server
+}
+
+// Create a Watcher for the servers returned by @fetch.
+func NewWatcher(fetch func() ([]Server, error)) *Watcher {
+	return &Watcher{ fetch: fetch }
+}
+
+// Create a Watcher for the servers @names.
+// @acctAlias: The alias of the account that owns the servers (optional).
+func (c *Client) NewServerWatcher(names []string, acctAlias string) *Watcher {
+	return NewWatcher(func() (servers []Server, err error) {
+		for _, name := range names {
+			s, err := c.GetServer(name, acctAlias)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to look up server %s: %s", name, err)
+			}
+			servers = append(servers, s)
+		}
+		return
+	})
+}
+
+// Create a Watcher for all servers of hardware group @ref (root group if empty), including those in sub-groups.
+// @ref:       The group UUID, name or path (see LookupGroupNode).
+// @location:  The data center location of the group (not needed if @ref is a path).
+// @acctAlias: The alias of the account that owns the group (optional).
+func (c *Client) NewGroupWatcher(ref, location, acctAlias string) *Watcher {
+	return NewWatcher(func() (servers []Server, err error) {
+		group, err := c.LookupGroupNode(ref, location, acctAlias, true)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range group.AllServers(false) {
+			servers = append(servers, *s)
+		}
+		return
+	})
+}
+
+// Fetch the current state, and compare it against the previous refresh.
+// On the first refresh, all servers are reported as unchanged.
+func (w *Watcher) Refresh() (*WatchUpdate, error) {
+	servers, err := w.fetch()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Name < servers[j].Name })
+
+	u := &WatchUpdate{ When: time.Now(), Servers: servers, Changes: make(map[string][]FieldChange) }
+	cur := make(map[string]Server)
+	for _, s := range servers {
+		cur[s.Name] = s
+		if w.last == nil {
+			continue
+		} else if prev, ok := w.last[s.Name]; !ok {
+			u.Added = append(u.Added, s.Name)
+		} else if changes := DiffServers(&prev, &s); len(changes) > 0 {
+			u.Changes[s.Name] = changes
+		}
+	}
+	for name := range w.last {
+		if _, ok := cur[name]; !ok {
+			u.Removed = append(u.Removed, name)
+		}
+	}
+	sort.Strings(u.Removed)
+
+	w.last = cur
+	return u, nil
+}
+
+// Refresh every @interval, passing each update to @show, until @until holds (if non-nil)
+// or a refresh fails. Returns nil once @until is met.
+func (w *Watcher) Run(interval time.Duration, until WatchCondition, show func(*WatchUpdate)) error {
+	for {
+		u, err := w.Refresh()
+		if err != nil {
+			return err
+		}
+		show(u)
+
+		if until != nil && until(u.Servers) {
+			return nil
+		}
+		time.Sleep(interval)
+	}
+}